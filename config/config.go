@@ -2,6 +2,7 @@ package config
 
 import(
 	"fmt"
+	"time"
 	"github.com/caarlos0/env/v11"
 )
 
@@ -13,6 +14,7 @@ type (
 		PG PG
 		Swagger Swagger
 		Metrics Metrics
+		Idempotency Idempotency
 	}
 
 	App struct {
@@ -41,6 +43,10 @@ type (
 	Metrics struct {
 		Enabled bool `env:"METRICS_ENABLED" envDefault:"false"`
 	}
+
+	Idempotency struct {
+		KeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	}
 )
 
 
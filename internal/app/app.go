@@ -29,6 +29,9 @@ func Run(cfg *config.Config) {
 	//Usecase
 	SubscriptionUsecase := subscriptionservice.New(
 		persistence.New(pg),
+		persistence.NewExchangeRateRepo(pg),
+		persistence.NewTaxRateRepo(pg),
+		persistence.NewIdempotencyRepo(pg),
 	)
 
 	//http server
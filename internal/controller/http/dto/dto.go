@@ -1,12 +1,27 @@
 package dto
 
+import "time"
+
 // Store
 type StoreSubscriptionHandlerRequest struct {
-	ServiceName string `json:"service_name" validate:"required"`
-	Price       string `json:"price" validate:"required"`
-	UserId      string `json:"user_id" validate:"required"`
-	StartDate   string `json:"start_date" validate:"required"`
-	EndDate     string `json:"end_date"`
+	ServiceName            string            `json:"service_name" validate:"required"`
+	PlanID                 string            `json:"plan_id"`                                                       // plan of the service, its list price is taken unless overridden
+	Price                  string            `json:"price"`                                                         // decimal in major units such as 299.99, required without a plan
+	Currency               string            `json:"currency"`                                                      // ISO 4217, default: currency of the plan or RUB
+	BillingPeriod          string            `json:"billing_period" enums:"weekly,monthly,quarterly,yearly,custom"` // default: billing period of the plan or monthly
+	BillingPeriodDays      string            `json:"billing_period_days"`                                           // required for custom
+	UserId                 string            `json:"user_id" validate:"required"`
+	StartDate              string            `json:"start_date" validate:"required"`
+	EndDate                string            `json:"end_date"`
+	TrialStartDate         string            `json:"trial_start_date"` // default: start_date
+	TrialEndDate           string            `json:"trial_end_date"`
+	TaxRegion              string            `json:"tax_region"`               // key of the tax rate, e.g. DE or US-CA, default: untaxed
+	TaxInclusive           string            `json:"tax_inclusive"`            // whether the price includes the tax, default: true
+	PaymentMethodID        string            `json:"payment_method_id"`        // payment method of the user
+	AutoRenew              string            `json:"auto_renew"`               // default: true, a fixed-term subscription needs an end date
+	CancellationNoticeDays string            `json:"cancellation_notice_days"` // days before a renewal it has to be cancelled by, default: 0
+	Notes                  string            `json:"notes"`
+	Attributes             map[string]string `json:"attributes"` // custom metadata such as account_email or invoice_ref, keys of letters, digits, _ and -
 }
 
 type StoreSubscriptionHandlerResponse struct {
@@ -21,22 +36,55 @@ type GetSubscriptionHandlerRequest struct {
 }
 
 type GetSubscriptionHandlerResponse struct {
-	ServiceName string `json:"service_name" validate:"required"`
-	Price       string `json:"price" validate:"required"`
-	UserId      string `json:"user_id" validate:"required"`
-	StartDate   string `json:"start_date" validate:"required"`
-	EndDate     string `json:"end_date"`
+	ServiceID              string            `json:"service_id"`
+	ServiceName            string            `json:"service_name" validate:"required"`
+	PlanID                 string            `json:"plan_id,omitempty"`
+	Price                  string            `json:"price" validate:"required"`
+	Currency               string            `json:"currency" validate:"required"`
+	BillingPeriod          string            `json:"billing_period" validate:"required"`
+	BillingPeriodDays      string            `json:"billing_period_days,omitempty"`
+	Status                 string            `json:"status" validate:"required"`
+	UserId                 string            `json:"user_id" validate:"required"`
+	StartDate              string            `json:"start_date" validate:"required"`
+	EndDate                string            `json:"end_date"`
+	TrialStartDate         string            `json:"trial_start_date,omitempty"`
+	TrialEndDate           string            `json:"trial_end_date,omitempty"`
+	TaxRegion              string            `json:"tax_region,omitempty"`
+	TaxInclusive           string            `json:"tax_inclusive"`
+	PaymentMethodID        string            `json:"payment_method_id,omitempty"`
+	Tags                   []string          `json:"tags"`
+	NextBillingDate        string            `json:"next_billing_date,omitempty"` // empty once the subscription is not billed anymore
+	RemainingCharges       string            `json:"remaining_charges,omitempty"` // empty for an open-ended subscription
+	AutoRenew              string            `json:"auto_renew"`
+	CancellationNoticeDays string            `json:"cancellation_notice_days"`
+	CancellationDeadline   string            `json:"cancellation_deadline,omitempty"` // last day to cancel before the next renewal, empty if nothing renews
+	Notes                  string            `json:"notes,omitempty"`
+	Attributes             map[string]string `json:"attributes"`
+	ETag                   string            `json:"etag"` // version to send back in If-Match
 }
 
 //--------------------------------------------------------------------------
 
-// Update
+// Update replaces a subscription, what is left out is reset to its default
 type UpdateSubscriptionHandlerRequest struct {
-	ServiceName string `json:"service_name"`
-	Price       string `json:"price"`
-	UserId      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
+	ServiceName            string            `json:"service_name" validate:"required"`
+	PlanID                 string            `json:"plan_id"`                                                       // plan of the service, its list price is taken unless overridden
+	Price                  string            `json:"price"`                                                         // decimal in major units such as 299.99, required without a plan
+	Currency               string            `json:"currency"`                                                      // ISO 4217, default: currency of the plan or RUB
+	BillingPeriod          string            `json:"billing_period" enums:"weekly,monthly,quarterly,yearly,custom"` // default: billing period of the plan or monthly
+	BillingPeriodDays      string            `json:"billing_period_days"`                                           // required for custom
+	UserId                 string            `json:"user_id" validate:"required"`
+	StartDate              string            `json:"start_date" validate:"required"`
+	EndDate                string            `json:"end_date"`
+	TrialStartDate         string            `json:"trial_start_date"` // default: start_date
+	TrialEndDate           string            `json:"trial_end_date"`
+	TaxRegion              string            `json:"tax_region"`               // key of the tax rate, e.g. DE or US-CA, default: untaxed
+	TaxInclusive           string            `json:"tax_inclusive"`            // whether the price includes the tax, default: true
+	PaymentMethodID        string            `json:"payment_method_id"`        // payment method of the user
+	AutoRenew              string            `json:"auto_renew"`               // default: true, a fixed-term subscription needs an end date
+	CancellationNoticeDays string            `json:"cancellation_notice_days"` // days before a renewal it has to be cancelled by, default: 0
+	Notes                  string            `json:"notes"`
+	Attributes             map[string]string `json:"attributes"` // custom metadata such as account_email or invoice_ref, keys of letters, digits, _ and -
 }
 
 //--------------------------------------------------------------------------
@@ -48,21 +96,86 @@ type DeleteSubscriptionHandlerRequest struct {
 
 //--------------------------------------------------------------------------
 
-// List
+// Batch
+type BatchSubscriptionsHandlerRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"required"` // run in order, at most 100
+}
+
+type BatchOperation struct {
+	Op           string                           `json:"op" enums:"create,update,delete" validate:"required"`
+	ID           string                           `json:"id"`           // subscription to update or delete
+	IfMatch      string                           `json:"if_match"`     // ETag the subscription to update or delete has to match
+	Subscription *StoreSubscriptionHandlerRequest `json:"subscription"` // to create, or to replace the one with id with
+}
+
+type BatchSubscriptionsHandlerResponse struct {
+	Results []BatchOperationResult `json:"results"` // one per operation, in their order
+}
+
+type BatchOperationResult struct {
+	Op     string `json:"op"`
+	Status int    `json:"status"` // HTTP status of the operation, 424 for one undone or not run in an atomic batch
+	ID     string `json:"id,omitempty"`
+	ETag   string `json:"etag,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+//--------------------------------------------------------------------------
+
+// Import
+type ImportSubscriptionsHandlerRequest struct {
+	Mapping    string `form:"mapping"`     // JSON object of field to CSV column, default: the columns named as a field
+	DateFormat string `form:"date_format"` // e.g. DD.MM.YYYY or MM/DD/YYYY, default: RFC3339
+	Delimiter  string `form:"delimiter"`   // default: ,
+
+	DryRun     bool                              `form:"-"`
+	DateLayout string                            `form:"-"` // DateFormat as a time layout
+	Rows       []StoreSubscriptionHandlerRequest `form:"-"`
+	Lines      []int                             `form:"-"` // line of each row in the file
+	Errors     []string                          `form:"-"` // why a row could not be read, empty for one that could
+}
+
+type ImportSubscriptionsHandlerResponse struct {
+	DryRun    bool              `json:"dry_run"`
+	Valid     int               `json:"valid"` // rows imported, or to be imported on a dry run
+	Invalid   int               `json:"invalid"`
+	Duplicate int               `json:"duplicate"` // rows of an existing subscription or repeating an earlier row
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+type ImportRowResult struct {
+	Line   int    `json:"line"` // the header is line 1
+	Status string `json:"status" enums:"valid,invalid,duplicate"`
+	ID     string `json:"id,omitempty"` // of the imported subscription
+	Error  string `json:"error,omitempty"`
+}
+
 type ListSubscriptionsHandlerRequest struct {
 	// pagination
 	Page     int `query:"page" validate:"min=1"`
 	PageSize int `query:"page_size" validate:"min=1,max=100"`
 
 	// filters
-	ServiceName *string    `query:"service_name"`
+	ServiceName *string `query:"service_name"`
 	UserID      *string `query:"user_id"`
-	Price       *string    `query:"price_min"`
-	StartDate   *string    `query:"start_date"` // format: YYYY-MM-DD
-	EndDate     *string    `query:"end_date"`   // format: YYYY-MM-DD
+	Price       *string `query:"price_min"`
+	StartDate   *string `query:"start_date"` // format: YYYY-MM-DD
+	EndDate     *string `query:"end_date"`   // format: YYYY-MM-DD
+
+	TrialEndsWithinDays            *int    `query:"trial_ends_within_days"`
+	CancellationDeadlineWithinDays *int    `query:"cancellation_deadline_within_days"` // auto-renewing subscriptions to cancel within the days to avoid a renewal
+	Tags                           *string `query:"tags"`                              // comma separated, any of them
+
+	NextBillingFrom *string `query:"next_billing_from"` // format: YYYY-MM-DD
+	NextBillingTo   *string `query:"next_billing_to"`   // format: YYYY-MM-DD, inclusive
+
+	TagNames            []string          `query:"-"`
+	Attributes          map[string]string `query:"-"` // from the attr.<key>=<value> parameters
+	NextBillingFromDate *time.Time        `query:"-"`
+	NextBillingToDate   *time.Time        `query:"-"`
 
 	// sort
-	SortBy    string `query:"sort_by"`    // field name
+	SortBy    string `query:"sort_by"`    // start_date, end_date, created_at, service_name, price, next_billing_date
 	SortOrder string `query:"sort_order"` // asc, desc
 }
 
@@ -75,12 +188,33 @@ type ListSubscriptionsHandlerResponse struct {
 }
 
 type SubscriptionItem struct {
-	ID          string `json:"id"`
-	ServiceName string `json:"service_name"`
-	Price       string `json:"price"`
-	UserID      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
+	ID                     string            `json:"id"`
+	ServiceID              string            `json:"service_id"`
+	ServiceName            string            `json:"service_name"`
+	PlanID                 string            `json:"plan_id,omitempty"`
+	Price                  string            `json:"price"`
+	Currency               string            `json:"currency"`
+	BillingPeriod          string            `json:"billing_period"`
+	BillingPeriodDays      string            `json:"billing_period_days,omitempty"`
+	Status                 string            `json:"status"`
+	UserID                 string            `json:"user_id"`
+	StartDate              string            `json:"start_date"`
+	EndDate                string            `json:"end_date"`
+	TrialStartDate         string            `json:"trial_start_date,omitempty"`
+	TrialEndDate           string            `json:"trial_end_date,omitempty"`
+	TaxRegion              string            `json:"tax_region,omitempty"`
+	TaxInclusive           string            `json:"tax_inclusive"`
+	PaymentMethodID        string            `json:"payment_method_id,omitempty"`
+	Tags                   []string          `json:"tags"`
+	UserShare              string            `json:"user_share,omitempty"` // part of the current price paid by the user the list is filtered by
+	NextBillingDate        string            `json:"next_billing_date,omitempty"`
+	RemainingCharges       string            `json:"remaining_charges,omitempty"`
+	AutoRenew              string            `json:"auto_renew"`
+	CancellationNoticeDays string            `json:"cancellation_notice_days"`
+	CancellationDeadline   string            `json:"cancellation_deadline,omitempty"`
+	Notes                  string            `json:"notes,omitempty"`
+	Attributes             map[string]string `json:"attributes"`
+	ETag                   string            `json:"etag"` // version to send back in If-Match
 }
 
 //--------------------------------------------------------------------------
@@ -93,18 +227,36 @@ type ErrorResponse struct {
 
 //--------------------------------------------------------------------------
 
-// Total cost
-type TotalCostHandlerRequest struct {
+// Cost query, shared by the total cost and the cost breakdown
+type CostQueryHandlerRequest struct {
 	UserID      *string `query:"user_id"`
 	ServiceName *string `query:"service_name"`
+	Tags        *string `query:"tags"`                           // comma separated, any of them
 	StartDate   *string `query:"start_date" validate:"required"` // format: YYYY-MM-DD
 	EndDate     *string `query:"end_date" validate:"required"`   // format: YYYY-MM-DD
+	Basis       *string `query:"basis"`                          // cash, accrual
+	Currency    *string `query:"currency"`                       // ISO 4217, default: RUB
+	GroupBy     *string `query:"group_by"`                       // service_name, user_id, tag, payment_method
+
+	TagNames []string `query:"-"`
 }
 
+//--------------------------------------------------------------------------
+
+// Total cost
+type TotalCostHandlerRequest CostQueryHandlerRequest
+
 type TotalCostHandlerResponse struct {
-	TotalCost uint64           `json:"total_cost"`
-	Period    Period           `json:"period"`
-	Filters   TotalCostFilters `json:"filters"`
+	NetCost          string           `json:"net_cost"`
+	Tax              string           `json:"tax"`
+	GrossCost        string           `json:"gross_cost"`        // net_cost plus tax, with discounts taken off
+	UndiscountedCost string           `json:"undiscounted_cost"` // gross_cost before discounts
+	Currency         string           `json:"currency"`
+	Basis            string           `json:"basis"`
+	Period           Period           `json:"period"`
+	Filters          TotalCostFilters `json:"filters"`
+	GroupBy          string           `json:"group_by,omitempty"`
+	Groups           []GroupCost      `json:"groups,omitempty"` // gross_cost by group
 }
 
 type Period struct {
@@ -113,6 +265,268 @@ type Period struct {
 }
 
 type TotalCostFilters struct {
-	UserID      *string `json:"user_id,omitempty"`
-	ServiceName *string `json:"service_name,omitempty"`
+	UserID      *string  `json:"user_id,omitempty"`
+	ServiceName *string  `json:"service_name,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+//--------------------------------------------------------------------------
+
+// Cost breakdown
+type CostBreakdownHandlerRequest CostQueryHandlerRequest
+
+type CostBreakdownHandlerResponse struct {
+	Currency string           `json:"currency"`
+	Basis    string           `json:"basis"`
+	GroupBy  string           `json:"group_by,omitempty"`
+	Period   Period           `json:"period"`
+	Filters  TotalCostFilters `json:"filters"`
+	Months   []MonthlyCost    `json:"months"`
+}
+
+type MonthlyCost struct {
+	Month     string      `json:"month"` // format: YYYY-MM
+	TotalCost string      `json:"total_cost"`
+	Groups    []GroupCost `json:"groups,omitempty"`
+}
+
+type GroupCost struct {
+	Key       string `json:"key"`
+	TotalCost string `json:"total_cost"`
+}
+
+//--------------------------------------------------------------------------
+
+// Forecast
+type ForecastHandlerRequest struct {
+	UserID   *string `query:"user_id"`
+	Months   *int    `query:"months" validate:"min=1,max=60"` // default: 12
+	Currency *string `query:"currency"`                       // ISO 4217, default: RUB
+}
+
+type ForecastHandlerResponse struct {
+	Currency string           `json:"currency"`
+	Filters  TotalCostFilters `json:"filters"`
+	Months   []MonthlyCost    `json:"months"`
+}
+
+//--------------------------------------------------------------------------
+
+// Price changes
+type StorePriceChangeHandlerRequest struct {
+	Price         string `json:"price" validate:"required"`          // decimal in major units of the subscription currency
+	EffectiveDate string `json:"effective_date" validate:"required"` // format: RFC3339
+}
+
+type PriceChangeItem struct {
+	ID            string `json:"id"`
+	Price         string `json:"price"`
+	EffectiveDate string `json:"effective_date"`
+}
+
+type ListPriceChangesHandlerResponse struct {
+	PriceChanges []PriceChangeItem `json:"price_changes"`
+}
+
+// Shares
+type ShareItem struct {
+	UserID      string `json:"user_id" validate:"required,uuid"`
+	Weight      string `json:"weight,omitempty"`       // part of what the fixed amounts leave
+	FixedAmount string `json:"fixed_amount,omitempty"` // decimal paid of every charge
+}
+
+type ReplaceSharesHandlerRequest struct {
+	Shares []ShareItem `json:"shares"`
+}
+
+type ListSharesHandlerResponse struct {
+	Shares []ShareItem `json:"shares"`
+}
+
+//--------------------------------------------------------------------------
+
+// Discounts
+type DiscountHandlerRequest struct {
+	Kind      string `json:"kind" validate:"required" enums:"percent,fixed"`
+	Value     string `json:"value" validate:"required"` // percent off, or decimal amount off every charge
+	StartDate string `json:"start_date"`                // format: RFC3339, default: subscription start
+	EndDate   string `json:"end_date"`                  // format: RFC3339, exclusive, default: open-ended
+}
+
+type DiscountItem struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+}
+
+type ListDiscountsHandlerResponse struct {
+	Discounts []DiscountItem `json:"discounts"`
+}
+
+//--------------------------------------------------------------------------
+
+// Tags
+type StoreTagHandlerRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type UpdateTagHandlerRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type TagItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ListTagsHandlerResponse struct {
+	Tags []TagItem `json:"tags"`
+}
+
+type ReplaceSubscriptionTagsHandlerRequest struct {
+	Tags []string `json:"tags"` // names, missing tags are created
+}
+
+//--------------------------------------------------------------------------
+
+// Budgets
+type BudgetHandlerRequest struct {
+	MonthlyLimit string `json:"monthly_limit" validate:"required"` // decimal in major units
+	Currency     string `json:"currency"`                          // ISO 4217, default: RUB
+	Tag          string `json:"tag"`                               // limits the budget to subscriptions with this tag
+	ServiceName  string `json:"service_name"`                      // limits the budget to this service
+}
+
+type BudgetItem struct {
+	ID           string `json:"id"`
+	UserID       string `json:"user_id"`
+	MonthlyLimit string `json:"monthly_limit"`
+	Currency     string `json:"currency"`
+	Tag          string `json:"tag,omitempty"`
+	ServiceName  string `json:"service_name,omitempty"`
+}
+
+type ListBudgetsHandlerResponse struct {
+	Budgets []BudgetItem `json:"budgets"`
+}
+
+type BudgetReportHandlerRequest struct {
+	StartMonth *string `query:"start_month"` // format: YYYY-MM, default: current month
+	EndMonth   *string `query:"end_month"`   // format: YYYY-MM, inclusive, default: start_month
+	Basis      *string `query:"basis"`       // cash, accrual
+}
+
+type BudgetReportHandlerResponse struct {
+	UserID     string            `json:"user_id"`
+	Basis      string            `json:"basis"`
+	StartMonth string            `json:"start_month"`
+	EndMonth   string            `json:"end_month"`
+	Overspent  bool              `json:"overspent"` // whether any budget was exceeded
+	Budgets    []BudgetUsageItem `json:"budgets"`
+}
+
+type BudgetUsageItem struct {
+	Budget    BudgetItem        `json:"budget"`
+	Overspent bool              `json:"overspent"`
+	Months    []BudgetMonthItem `json:"months"`
+}
+
+type BudgetMonthItem struct {
+	Month     string `json:"month"` // format: YYYY-MM
+	Limit     string `json:"limit"`
+	Spent     string `json:"spent"`
+	Overspent bool   `json:"overspent"`
+	Overspend string `json:"overspend"` // spent above the limit
+}
+
+//--------------------------------------------------------------------------
+
+// Services
+type ServiceHandlerRequest struct {
+	Name    string   `json:"name" validate:"required"`
+	Aliases []string `json:"aliases"` // other spellings, the name is always one of them
+}
+
+type ServiceItem struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+type ListServicesHandlerResponse struct {
+	Services []ServiceItem `json:"services"`
+}
+
+type MergeServicesHandlerRequest struct {
+	DuplicateIDs []string `json:"duplicate_ids" validate:"required"` // services folded into this one
+}
+
+//--------------------------------------------------------------------------
+
+// Plans
+type PlanHandlerRequest struct {
+	Name              string `json:"name" validate:"required"`
+	Price             string `json:"price" validate:"required"`                                     // decimal in major units such as 299.99
+	Currency          string `json:"currency"`                                                      // ISO 4217, default: RUB
+	BillingPeriod     string `json:"billing_period" enums:"weekly,monthly,quarterly,yearly,custom"` // default: monthly
+	BillingPeriodDays string `json:"billing_period_days"`                                           // required for custom
+}
+
+type PlanItem struct {
+	ID                string `json:"id"`
+	ServiceID         string `json:"service_id"`
+	Name              string `json:"name"`
+	Price             string `json:"price"`
+	Currency          string `json:"currency"`
+	BillingPeriod     string `json:"billing_period"`
+	BillingPeriodDays string `json:"billing_period_days,omitempty"`
+}
+
+type ListPlansHandlerResponse struct {
+	Plans []PlanItem `json:"plans"`
+}
+
+type PlanUsageHandlerRequest struct {
+	ServiceID *string `query:"service_id"` // default: every service
+	Date      *string `query:"date"`       // format: YYYY-MM-DD, default: today
+}
+
+type PlanUsageHandlerResponse struct {
+	Date  string          `json:"date"`
+	Plans []PlanUsageItem `json:"plans"`
+}
+
+type PlanUsageItem struct {
+	Plan          PlanItem `json:"plan"`
+	ServiceName   string   `json:"service_name"`
+	Users         int      `json:"users"`         // distinct owners of the active subscriptions
+	Subscriptions int      `json:"subscriptions"` // active on the date
+}
+
+//--------------------------------------------------------------------------
+
+// Payment methods
+type PaymentMethodHandlerRequest struct {
+	Type     string `json:"type" validate:"required" enums:"card,wallet,bank"`
+	Label    string `json:"label" validate:"required"`
+	LastFour string `json:"last_four"` // last four digits of the card or account number
+}
+
+type PaymentMethodItem struct {
+	ID       string `json:"id"`
+	UserID   string `json:"user_id"`
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	LastFour string `json:"last_four,omitempty"`
+	Masked   string `json:"masked,omitempty"` // e.g. **** 4242
+}
+
+type ListPaymentMethodsHandlerResponse struct {
+	PaymentMethods []PaymentMethodItem `json:"payment_methods"`
+}
+
+type PaymentMethodSubscriptionsHandlerResponse struct {
+	Subscriptions []SubscriptionItem `json:"subscriptions"` // paid with the payment method
 }
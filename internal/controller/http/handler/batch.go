@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/controller/http/dto"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batch creates, updates and deletes subscriptions in one request
+// @Summary Batch subscription changes
+// @Description Run up to 100 create, update and delete operations in order and get the outcome of each. An update replaces the subscription as PUT does. With atomic=true all of them run in one transaction, which is rolled back once one fails, and the response takes the status of the failed operation
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param atomic query bool false "Apply all operations or none" default(false)
+// @Param request body dto.BatchSubscriptionsHandlerRequest true "Operations"
+// @Param Idempotency-Key header string false "Unique key of the request, a retry with the same key and body gets the first response replayed"
+// @Success 200 {object} dto.BatchSubscriptionsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions:batch [post]
+func (h *SubscriptionHandler) Batch(ctx *fiber.Ctx) error {
+	const op = "handler.Batch"
+
+	operations, atomic, err := h.parser.ParseBatchRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse batch request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	results := make([]dto.BatchOperationResult, len(operations))
+	batchOps := make([]usecase.BatchOp, 0, len(operations))
+	positions := make([]int, 0, len(operations))
+	failed := -1
+	for i, operation := range operations {
+		results[i].Op = operation.Op
+		batchOp, err := h.parser.ParseBatchOperation(ctx, operation)
+		if err != nil {
+			h.logger.Error("failed to parse batch operation", "operation", op, "index", i, "error", err)
+			results[i].Status = err.(*fiber.Error).Code
+			results[i].Error = err.Error()
+			if failed < 0 {
+				failed = i
+			}
+			continue
+		}
+		batchOps = append(batchOps, batchOp)
+		positions = append(positions, i)
+	}
+
+	// an atomic batch runs only if every operation is valid
+	if atomic && failed >= 0 {
+		batchOps = nil
+	}
+
+	outcomes, err := h.usecase.Batch(ctx.Context(), batchOps, atomic)
+	if err != nil {
+		h.logger.Error("failed to run batch", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to run batch")
+	}
+
+	for j, outcome := range outcomes {
+		i := positions[j]
+		if outcome.Err != nil {
+			h.logger.Error("batch operation failed", "operation", op, "index", i, "error", outcome.Err)
+			results[i].Status, results[i].Error = subscriptionErrorStatus(outcome.Err)
+			if failed < 0 || i < failed {
+				failed = i
+			}
+			continue
+		}
+
+		results[i].Status = batchSuccessStatus(batchOps[j].Kind)
+		results[i].ID = operations[i].ID
+		if outcome.Sub != nil {
+			results[i].ID = strconv.FormatInt(outcome.Sub.Id, 10)
+			results[i].ETag = h.mapper.ToETag(outcome.Sub)
+		}
+	}
+
+	status := fiber.StatusOK
+	if atomic && failed >= 0 {
+		for i := range results {
+			if results[i].Error == "" {
+				results[i] = dto.BatchOperationResult{
+					Op:     results[i].Op,
+					Status: fiber.StatusFailedDependency,
+					Error:  "Not applied, operation " + strconv.Itoa(failed) + " failed",
+				}
+			}
+		}
+		status = results[failed].Status
+	}
+
+	h.logger.Info("batch run",
+		"operation", op,
+		"operations", len(operations),
+		"atomic", atomic,
+		"failed", failed,
+	)
+
+	return ctx.Status(status).JSON(dto.BatchSubscriptionsHandlerResponse{Results: results})
+}
+
+func batchSuccessStatus(kind usecase.BatchOpKind) int {
+	switch kind {
+	case usecase.BatchCreate:
+		return fiber.StatusCreated
+	case usecase.BatchDelete:
+		return fiber.StatusNoContent
+	default:
+		return fiber.StatusOK
+	}
+}
+
+// subscriptionErrorStatus answers a failed write of a subscription as the request
+// to write it alone would be answered.
+func subscriptionErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, entity.ErrSubscriptionNotFound):
+		return fiber.StatusNotFound, "Subscription not found"
+	case errors.Is(err, entity.ErrSubscriptionExists):
+		return fiber.StatusConflict, "Subscription to this service starting on this date already exists for the user"
+	case errors.Is(err, entity.ErrVersionMismatch):
+		return fiber.StatusPreconditionFailed, "Subscription was modified, fetch it again"
+	case errors.Is(err, entity.ErrSubscriptionEnded):
+		return fiber.StatusConflict, "End date of a cancelled or expired subscription cannot be changed"
+	case errors.Is(err, entity.ErrTaxRegionNotFound):
+		return fiber.StatusUnprocessableEntity, "Unknown tax region"
+	case errors.Is(err, entity.ErrPlanNotFound):
+		return fiber.StatusUnprocessableEntity, "Plan not found"
+	case errors.Is(err, entity.ErrPaymentMethodNotFound):
+		return fiber.StatusUnprocessableEntity, "Payment method not found for the user"
+	default:
+		return fiber.StatusInternalServerError, "Failed to apply operation"
+	}
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StoreBudget creates a new budget for a user
+// @Summary Create budget
+// @Description Set a monthly spending limit for a user, optionally limited to a tag or a service
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body dto.BudgetHandlerRequest true "Budget data"
+// @Success 201 {object} dto.BudgetItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/budgets [post]
+func (h *SubscriptionHandler) StoreBudget(ctx *fiber.Ctx) error {
+	const op = "handler.StoreBudget"
+
+	budget, err := h.parser.ParseStoreBudgetRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse store budget request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.StoreBudget(ctx.Context(), budget)
+	switch {
+	case errors.Is(err, entity.ErrBudgetExists):
+		h.logger.Error("duplicate budget", "operation", op, "user_id", budget.UserID, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Budget with this scope already exists")
+	case errors.Is(err, entity.ErrTagNotFound):
+		h.logger.Error("unknown budget tag", "operation", op, "tag", budget.Tag, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Tag not found")
+	case err != nil:
+		h.logger.Error("failed to store budget", "operation", op, "user_id", budget.UserID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create budget")
+	}
+
+	h.logger.Info("budget created successfully",
+		"operation", op,
+		"budget_id", budget.Id,
+		"user_id", budget.UserID,
+	)
+
+	return ctx.Status(fiber.StatusCreated).JSON(h.mapper.ToBudgetItem(budget))
+}
+
+// ListBudgets retrieves the budgets of a user
+// @Summary List budgets
+// @Description Get every budget of a user
+// @Tags budgets
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} dto.ListBudgetsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/budgets [get]
+func (h *SubscriptionHandler) ListBudgets(ctx *fiber.Ctx) error {
+	const op = "handler.ListBudgets"
+
+	userID, err := h.parser.ParseUserRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse list budgets request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	budgets, err := h.usecase.ListBudgets(ctx.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list budgets", "operation", op, "user_id", userID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get budgets")
+	}
+
+	h.logger.Info("budgets listed successfully",
+		"operation", op,
+		"user_id", userID,
+		"count", len(budgets),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToListBudgetsResponse(budgets))
+}
+
+// GetBudget retrieves a budget by ID
+// @Summary Get budget
+// @Description Get budget of a user by ID
+// @Tags budgets
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param id path int true "Budget ID"
+// @Success 200 {object} dto.BudgetItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/budgets/{id} [get]
+func (h *SubscriptionHandler) GetBudget(ctx *fiber.Ctx) error {
+	const op = "handler.GetBudget"
+
+	userID, id, err := h.parser.ParseBudgetIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse get budget request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	budget, err := h.usecase.GetBudget(ctx.Context(), userID, id)
+	switch {
+	case errors.Is(err, entity.ErrBudgetNotFound):
+		h.logger.Error("budget not found", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Budget not found")
+	case err != nil:
+		h.logger.Error("failed to get budget", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get budget")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToBudgetItem(budget))
+}
+
+// UpdateBudget updates a budget
+// @Summary Update budget
+// @Description Change the limit, currency or scope of a budget
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param id path int true "Budget ID"
+// @Param request body dto.BudgetHandlerRequest true "Budget data"
+// @Success 200 {object} dto.BudgetItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/budgets/{id} [put]
+func (h *SubscriptionHandler) UpdateBudget(ctx *fiber.Ctx) error {
+	const op = "handler.UpdateBudget"
+
+	budget, err := h.parser.ParseUpdateBudgetRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse update budget request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.UpdateBudget(ctx.Context(), budget)
+	switch {
+	case errors.Is(err, entity.ErrBudgetNotFound):
+		h.logger.Error("budget not found for update", "operation", op, "id", budget.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Budget not found")
+	case errors.Is(err, entity.ErrBudgetExists):
+		h.logger.Error("duplicate budget", "operation", op, "id", budget.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Budget with this scope already exists")
+	case errors.Is(err, entity.ErrTagNotFound):
+		h.logger.Error("unknown budget tag", "operation", op, "tag", budget.Tag, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Tag not found")
+	case err != nil:
+		h.logger.Error("failed to update budget", "operation", op, "id", budget.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update budget")
+	}
+
+	h.logger.Info("budget updated successfully",
+		"operation", op,
+		"budget_id", budget.Id,
+		"user_id", budget.UserID,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToBudgetItem(budget))
+}
+
+// DeleteBudget deletes a budget
+// @Summary Delete budget
+// @Description Delete budget of a user by ID
+// @Tags budgets
+// @Param user_id path string true "User ID"
+// @Param id path int true "Budget ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/budgets/{id} [delete]
+func (h *SubscriptionHandler) DeleteBudget(ctx *fiber.Ctx) error {
+	const op = "handler.DeleteBudget"
+
+	userID, id, err := h.parser.ParseBudgetIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse delete budget request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.DeleteBudget(ctx.Context(), userID, id)
+	switch {
+	case errors.Is(err, entity.ErrBudgetNotFound):
+		h.logger.Error("budget not found for deletion", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Budget not found")
+	case err != nil:
+		h.logger.Error("failed to delete budget", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete budget")
+	}
+
+	h.logger.Info("budget deleted successfully",
+		"operation", op,
+		"budget_id", id,
+		"user_id", userID,
+	)
+
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// BudgetReport compares the monthly spend of a user with their budgets
+// @Summary Budget report
+// @Description Compare the spend of a user in each month, calculated as for the total cost, with every budget and flag overspend
+// @Tags budgets
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param start_month query string false "First month (YYYY-MM), default: current month"
+// @Param end_month query string false "Last month (YYYY-MM), inclusive, default: start_month"
+// @Param basis query string false "Cost basis: cash counts charges on their billing dates, accrual prorates them by day" default(cash) Enums(cash, accrual)
+// @Success 200 {object} dto.BudgetReportHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/budgets/report [get]
+func (h *SubscriptionHandler) BudgetReport(ctx *fiber.Ctx) error {
+	const op = "handler.BudgetReport"
+
+	userID, req, err := h.parser.ParseBudgetReportRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse budget report request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	report, err := h.usecase.BudgetReport(ctx.Context(), userID, *req.StartMonth, *req.EndMonth, entity.CostBasis(*req.Basis))
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		h.logger.Error("failed to convert budget spend", "operation", op, "user_id", userID, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "No exchange rate to the budget currency for one of the subscriptions")
+	}
+	if err != nil {
+		h.logger.Error("failed to build budget report", "operation", op, "user_id", userID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to build budget report")
+	}
+
+	response := h.mapper.ToBudgetReportResponse(userID, report, req)
+
+	h.logger.Info("budget report built successfully",
+		"operation", op,
+		"user_id", userID,
+		"budgets", len(report),
+		"overspent", response.Overspent,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
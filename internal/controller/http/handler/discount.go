@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StoreDiscount adds a discount to a subscription
+// @Summary Add discount
+// @Description Take a percentage or a fixed amount off the charges billed within an optional date range
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body dto.DiscountHandlerRequest true "Discount data"
+// @Success 201 {object} dto.DiscountItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/discounts [post]
+func (h *SubscriptionHandler) StoreDiscount(ctx *fiber.Ctx) error {
+	const op = "handler.StoreDiscount"
+
+	discount, err := h.parser.ParseStoreDiscountRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse discount request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.AddDiscount(ctx.Context(), discount)
+	switch {
+	case errors.Is(err, entity.ErrSubscriptionNotFound):
+		h.logger.Error("subscription not found for discount", "operation", op, "id", discount.SubscriptionID, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
+	case err != nil:
+		h.logger.Error("failed to store discount", "operation", op, "id", discount.SubscriptionID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create discount")
+	}
+
+	response := h.mapper.ToDiscountItem(discount)
+
+	h.logger.Info("discount created successfully",
+		"operation", op,
+		"subscription_id", discount.SubscriptionID,
+		"discount_id", discount.Id,
+	)
+
+	return ctx.Status(fiber.StatusCreated).JSON(response)
+}
+
+// ListDiscounts retrieves the discounts of a subscription
+// @Summary List discounts
+// @Description Get the discounts of a subscription
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} dto.ListDiscountsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/discounts [get]
+func (h *SubscriptionHandler) ListDiscounts(ctx *fiber.Ctx) error {
+	const op = "handler.ListDiscounts"
+
+	id, err := h.parser.ParseGetRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse list discounts request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	discounts, err := h.usecase.ListDiscounts(ctx.Context(), int64(id))
+	if err != nil {
+		h.logger.Error("failed to list discounts", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get discounts")
+	}
+
+	response := h.mapper.ToListDiscountsResponse(discounts)
+
+	h.logger.Info("discounts listed successfully",
+		"operation", op,
+		"subscription_id", id,
+		"count", len(discounts),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetDiscount retrieves a discount of a subscription
+// @Summary Get discount
+// @Description Get a discount of a subscription by ID
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param discount_id path int true "Discount ID"
+// @Success 200 {object} dto.DiscountItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/discounts/{discount_id} [get]
+func (h *SubscriptionHandler) GetDiscount(ctx *fiber.Ctx) error {
+	const op = "handler.GetDiscount"
+
+	id, discountID, err := h.parser.ParseDiscountIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse get discount request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	discount, err := h.usecase.GetDiscount(ctx.Context(), id, discountID)
+	switch {
+	case errors.Is(err, entity.ErrDiscountNotFound):
+		h.logger.Error("discount not found", "operation", op, "id", id, "discount_id", discountID, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Discount not found")
+	case err != nil:
+		h.logger.Error("failed to get discount", "operation", op, "id", id, "discount_id", discountID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get discount")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToDiscountItem(discount))
+}
+
+// UpdateDiscount replaces a discount of a subscription
+// @Summary Update discount
+// @Description Replace the kind, value and date range of a discount
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param discount_id path int true "Discount ID"
+// @Param request body dto.DiscountHandlerRequest true "Discount data"
+// @Success 200 {object} dto.DiscountItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/discounts/{discount_id} [put]
+func (h *SubscriptionHandler) UpdateDiscount(ctx *fiber.Ctx) error {
+	const op = "handler.UpdateDiscount"
+
+	discount, err := h.parser.ParseUpdateDiscountRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse update discount request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.UpdateDiscount(ctx.Context(), discount)
+	switch {
+	case errors.Is(err, entity.ErrDiscountNotFound):
+		h.logger.Error("discount not found for update", "operation", op, "id", discount.SubscriptionID, "discount_id", discount.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Discount not found")
+	case err != nil:
+		h.logger.Error("failed to update discount", "operation", op, "id", discount.SubscriptionID, "discount_id", discount.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update discount")
+	}
+
+	h.logger.Info("discount updated successfully",
+		"operation", op,
+		"subscription_id", discount.SubscriptionID,
+		"discount_id", discount.Id,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToDiscountItem(discount))
+}
+
+// DeleteDiscount removes a discount from a subscription
+// @Summary Delete discount
+// @Description Delete a discount of a subscription by ID
+// @Tags subscriptions
+// @Param id path int true "Subscription ID"
+// @Param discount_id path int true "Discount ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/discounts/{discount_id} [delete]
+func (h *SubscriptionHandler) DeleteDiscount(ctx *fiber.Ctx) error {
+	const op = "handler.DeleteDiscount"
+
+	id, discountID, err := h.parser.ParseDiscountIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse delete discount request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.DeleteDiscount(ctx.Context(), id, discountID)
+	switch {
+	case errors.Is(err, entity.ErrDiscountNotFound):
+		h.logger.Error("discount not found for deletion", "operation", op, "id", id, "discount_id", discountID, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Discount not found")
+	case err != nil:
+		h.logger.Error("failed to delete discount", "operation", op, "id", id, "discount_id", discountID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete discount")
+	}
+
+	h.logger.Info("discount deleted successfully",
+		"operation", op,
+		"subscription_id", id,
+		"discount_id", discountID,
+	)
+
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"slices"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo/persistence"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -13,8 +18,12 @@ import (
 // @Accept json
 // @Produce json
 // @Param request body dto.StoreSubscriptionHandlerRequest true "Subscription data"
+// @Param Idempotency-Key header string false "Unique key of the request, a retry with the same key and body gets the first response replayed for 24 hours by default"
 // @Success 201 {object} dto.StoreSubscriptionHandlerResponse
+// @Header 201 {string} Idempotent-Replayed "true when the response is replayed for a retry"
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) Store(ctx *fiber.Ctx) error {
@@ -26,6 +35,22 @@ func (h *SubscriptionHandler) Store(ctx *fiber.Ctx) error {
 	}
 
 	err = h.usecase.Store(ctx.Context(), sub)
+	if errors.Is(err, entity.ErrSubscriptionExists) {
+		h.logger.Error("duplicate subscription", "operation", op, "service_name", sub.ServiceName, "user_id", sub.UserID, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Subscription to this service starting on this date already exists for the user")
+	}
+	if errors.Is(err, entity.ErrTaxRegionNotFound) {
+		h.logger.Error("unknown tax region", "operation", op, "tax_region", sub.TaxRegion, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Unknown tax region "+sub.TaxRegion)
+	}
+	if errors.Is(err, entity.ErrPlanNotFound) {
+		h.logger.Error("plan deleted meanwhile", "operation", op, "plan_id", sub.PlanID, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Plan not found")
+	}
+	if errors.Is(err, entity.ErrPaymentMethodNotFound) {
+		h.logger.Error("unknown payment method", "operation", op, "payment_method_id", sub.PaymentMethodID, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Payment method not found for the user")
+	}
 	if err != nil {
 		h.logger.Error("failed to store subscription", "operation", op, "error", err)
 		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create subscription")
@@ -50,6 +75,7 @@ func (h *SubscriptionHandler) Store(ctx *fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "Subscription ID"
 // @Success 200 {object} dto.GetSubscriptionHandlerResponse
+// @Header 200 {string} ETag "Version of the subscription, to send back in If-Match"
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -70,6 +96,7 @@ func (h *SubscriptionHandler) Get(ctx *fiber.Ctx) error {
 	}
 
 	response := h.mapper.ToGetResponse(sub)
+	ctx.Set(fiber.HeaderETag, h.mapper.ToETag(sub))
 
 	h.logger.Info("subscription retrieved successfully",
 		"operation", op,
@@ -80,22 +107,56 @@ func (h *SubscriptionHandler) Get(ctx *fiber.Ctx) error {
 }
 
 
-// Update updates a subscription
-// @Summary Update subscription
-// @Description Update subscription by ID
+// Update replaces a subscription
+// @Summary Replace subscription
+// @Description Replace every field of a subscription, what is left out is reset to its default as on creation
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param id path int true "Subscription ID"
 // @Param request body dto.UpdateSubscriptionHandlerRequest true "Subscription data"
+// @Param If-Match header string false "ETag of the subscription as last read, the request fails with 412 once it was modified"
 // @Success 200 {object} dto.GetSubscriptionHandlerResponse
+// @Header 200 {string} ETag "Version of the subscription, to send back in If-Match"
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /subscriptions/{id} [put]
 func (h *SubscriptionHandler) Update(ctx *fiber.Ctx) error {
-	const op = "handler.Update"
+	return h.update(ctx, "handler.Update", h.parser.ParseUpdateRequest)
+}
+
+// Patch partially updates a subscription
+// @Summary Patch subscription
+// @Description Update the given fields of a subscription as a JSON Merge Patch (RFC 7396), null removes a value such as end_date
+// @Tags subscriptions
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body dto.UpdateSubscriptionHandlerRequest true "Fields to change, null removes a value"
+// @Param If-Match header string false "ETag of the subscription as last read, the request fails with 412 once it was modified"
+// @Success 200 {object} dto.GetSubscriptionHandlerResponse
+// @Header 200 {string} ETag "Version of the subscription, to send back in If-Match"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id} [patch]
+func (h *SubscriptionHandler) Patch(ctx *fiber.Ctx) error {
+	return h.update(ctx, "handler.Patch", h.parser.ParsePatchRequest)
+}
 
+func (h *SubscriptionHandler) update(
+	ctx *fiber.Ctx,
+	op string,
+	parse func(ctx *fiber.Ctx, existingSub *entity.Subscription) error,
+) error {
 	id, err := h.parser.ParseGetRequest(ctx)
 	if err != nil {
 		h.logger.Error("failed to parse update request", "operation", op, "error", err)
@@ -108,19 +169,50 @@ func (h *SubscriptionHandler) Update(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
 	}
 
-	err = h.parser.ParseUpdateRequest(ctx, existingSub)
+	versions, err := h.parser.ParseIfMatchRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse update request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+	if len(versions) > 0 && !slices.Contains(versions, existingSub.Version) {
+		h.logger.Error("subscription version mismatch", "operation", op, "id", id, "version", existingSub.Version)
+		return errorResponse(ctx, fiber.StatusPreconditionFailed, "Subscription was modified, fetch it again")
+	}
+
+	err = parse(ctx, existingSub)
 	if err != nil {
 		h.logger.Error("failed to parse update request", "operation", op, "error", err)
 		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
 	}
 
 	err = h.usecase.Update(ctx.Context(), existingSub)
+	if errors.Is(err, entity.ErrTaxRegionNotFound) {
+		h.logger.Error("unknown tax region", "operation", op, "id", id, "tax_region", existingSub.TaxRegion, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Unknown tax region "+existingSub.TaxRegion)
+	}
+	if errors.Is(err, entity.ErrPlanNotFound) {
+		h.logger.Error("plan deleted meanwhile", "operation", op, "id", id, "plan_id", existingSub.PlanID, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Plan not found")
+	}
+	if errors.Is(err, entity.ErrPaymentMethodNotFound) {
+		h.logger.Error("unknown payment method", "operation", op, "id", id, "payment_method_id", existingSub.PaymentMethodID, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "Payment method not found for the user")
+	}
+	if errors.Is(err, entity.ErrVersionMismatch) {
+		h.logger.Error("subscription modified concurrently", "operation", op, "id", id, "version", existingSub.Version, "error", err)
+		return errorResponse(ctx, fiber.StatusPreconditionFailed, "Subscription was modified, fetch it again")
+	}
+	if errors.Is(err, entity.ErrSubscriptionNotFound) {
+		h.logger.Error("subscription deleted meanwhile", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
+	}
 	if err != nil {
 		h.logger.Error("failed to update subscription", "operation", op, "id", id, "error", err)
 		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update subscription")
 	}
 
 	response := h.mapper.ToUpdateResponse(existingSub)
+	ctx.Set(fiber.HeaderETag, h.mapper.ToETag(existingSub))
 
 	h.logger.Info("subscription updated successfully",
 		"operation", op,
@@ -137,9 +229,11 @@ func (h *SubscriptionHandler) Update(ctx *fiber.Ctx) error {
 // @Description Delete subscription by ID
 // @Tags subscriptions
 // @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the subscription as last read, the request fails with 412 once it was modified"
 // @Success 204
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /subscriptions/{id} [delete]
 func (h *SubscriptionHandler) Delete(ctx *fiber.Ctx) error {
@@ -151,7 +245,21 @@ func (h *SubscriptionHandler) Delete(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
 	}
 
-	err = h.usecase.Delete(ctx.Context(), id)
+	versions, err := h.parser.ParseIfMatchRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse delete request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.Delete(ctx.Context(), id, versions...)
+	if errors.Is(err, entity.ErrVersionMismatch) {
+		h.logger.Error("subscription version mismatch", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusPreconditionFailed, "Subscription was modified, fetch it again")
+	}
+	if errors.Is(err, entity.ErrSubscriptionNotFound) {
+		h.logger.Error("subscription not found for delete", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
+	}
 	if err != nil {
 		h.logger.Error("failed to delete subscription", "operation", op, "id", id, "error", err)
 		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete subscription")
@@ -173,9 +281,15 @@ func (h *SubscriptionHandler) Delete(ctx *fiber.Ctx) error {
 // @Produce json
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param page_size query int false "Page size" default(10) minimum(1) maximum(100)
-// @Param user_id query string false "User ID filter (UUID)"
+// @Param user_id query string false "User ID filter (UUID), includes the subscriptions shared with the user along with their share"
 // @Param service_name query string false "Service name filter"
-// @Param sort_by query string false "Sort field" default(start_date)
+// @Param trial_ends_within_days query int false "Only subscriptions whose free trial ends within this many days" minimum(0)
+// @Param tags query string false "Comma separated tag names, subscriptions labelled with any of them"
+// @Param attr.key query string false "Only subscriptions whose custom attribute key holds the value, any attr.<key> parameter filters by its key and all of them have to match"
+// @Param cancellation_deadline_within_days query int false "Only auto-renewing subscriptions that have to be cancelled within this many days, today included, to avoid a renewal" minimum(0)
+// @Param next_billing_from query string false "Only subscriptions billed next on or after this date (YYYY-MM-DD)"
+// @Param next_billing_to query string false "Only subscriptions billed next on or before this date (YYYY-MM-DD)"
+// @Param sort_by query string false "Sort field, subscriptions that are not billed anymore come last by next_billing_date, which is refused when more than 5000 subscriptions match the filters" default(start_date) Enums(start_date, end_date, created_at, service_name, price, next_billing_date)
 // @Param sort_order query string false "Sort order" default(desc) Enums(asc, desc)
 // @Success 200 {object} dto.ListSubscriptionsHandlerResponse
 // @Failure 400 {object} dto.ErrorResponse
@@ -192,26 +306,52 @@ func (h *SubscriptionHandler) List(ctx *fiber.Ctx) error {
 
 	opts := []persistence.ListOption{}
 	
+	var userID *uuid.UUID
 	if req.UserID != nil {
-		userID, err := uuid.Parse(*req.UserID)
+		id, err := uuid.Parse(*req.UserID)
 		if err != nil {
 			h.logger.Error("failed to parse list request", "operation", op, "error", err)
-			return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+			return errorResponse(ctx, fiber.StatusBadRequest, "Invalid user ID format")
 		}
-		opts = append(opts, persistence.WithUserID(userID))
+		userID = &id
+		opts = append(opts, persistence.WithUserID(id))
 	}
 	if req.ServiceName != nil {
 		opts = append(opts, persistence.WithServiceName(*req.ServiceName))
 	}
+	if req.TrialEndsWithinDays != nil {
+		opts = append(opts, persistence.WithTrialEndingWithin(*req.TrialEndsWithinDays))
+	}
+	if req.CancellationDeadlineWithinDays != nil {
+		opts = append(opts, persistence.WithCancellationDeadlineWithin(*req.CancellationDeadlineWithinDays))
+	}
+	if len(req.TagNames) > 0 {
+		opts = append(opts, persistence.WithTags(req.TagNames...))
+	}
+	for key, value := range req.Attributes {
+		opts = append(opts, persistence.WithAttribute(key, value))
+	}
+
+	page := usecase.ListPage{
+		SortBy:          req.SortBy,
+		SortOrder:       req.SortOrder,
+		Page:            req.Page,
+		PageSize:        req.PageSize,
+		NextBillingFrom: req.NextBillingFromDate,
+		NextBillingTo:   req.NextBillingToDate,
+	}
 
-	subscriptions, err := h.usecase.List(ctx.Context(), opts...)
+	subscriptions, total, err := h.usecase.List(ctx.Context(), page, opts...)
+	if errors.Is(err, entity.ErrTooManySubscriptions) {
+		h.logger.Error("too many subscriptions to list by next billing date", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusBadRequest, "Too many subscriptions match to filter or sort them by next billing date, narrow the filters down")
+	}
 	if err != nil {
 		h.logger.Error("failed to list subscriptions", "operation", op, "error", err)
 		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get subscriptions list")
 	}
 
-	total := len(subscriptions)
-	response := h.mapper.ToListResponse(subscriptions, total, req.Page, req.PageSize)
+	response := h.mapper.ToListResponse(subscriptions, userID, total, req.Page, req.PageSize)
 
 	h.logger.Info("subscriptions listed successfully",
 		"operation", op,
@@ -225,15 +365,20 @@ func (h *SubscriptionHandler) List(ctx *fiber.Ctx) error {
 
 // GetTotalCost calculates total cost of subscriptions for a period
 // @Summary Get total cost
-// @Description Calculate total cost of subscriptions for a specific period with optional filters
+// @Description Sum the charges billed within a specific period with optional filters, split into net and tax, with discounts taken off and before them
 // @Tags subscriptions
 // @Produce json
-// @Param user_id query string false "User ID filter (UUID)"
+// @Param user_id query string false "User ID filter (UUID), counts only the user's share of shared subscriptions"
 // @Param service_name query string false "Service name filter"
+// @Param tags query string false "Comma separated tag names, subscriptions labelled with any of them"
 // @Param start_date query string true "Start date (YYYY-MM-DD)"
 // @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param basis query string false "Cost basis: cash counts charges on their billing dates, accrual prorates them by day" default(cash) Enums(cash, accrual)
+// @Param currency query string false "ISO 4217 currency to convert amounts to" default(RUB)
+// @Param group_by query string false "Split the gross cost by, a subscription is counted under each of its tags, without a payment method under none" Enums(service_name, user_id, tag, payment_method)
 // @Success 200 {object} dto.TotalCostHandlerResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /subscriptions/total-cost [get]
 func (h *SubscriptionHandler) GetTotalCost(ctx *fiber.Ctx) error {
@@ -245,7 +390,19 @@ func (h *SubscriptionHandler) GetTotalCost(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
 	}
 
-	total, err := h.usecase.GetTotalCost(ctx.Context(), req.UserID, req.ServiceName, *req.StartDate, *req.EndDate)
+	total, err := h.usecase.GetTotalCost(ctx.Context(), usecase.CostFilter{
+		UserID:      req.UserID,
+		ServiceName: req.ServiceName,
+		Tags:        req.TagNames,
+		StartDate:   *req.StartDate,
+		EndDate:     *req.EndDate,
+		Basis:       entity.CostBasis(*req.Basis),
+		Currency:    *req.Currency,
+	}, entity.CostGroupBy(*req.GroupBy))
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		h.logger.Error("failed to convert total cost", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "No exchange rate to "+*req.Currency+" for one of the subscriptions")
+	}
 	if err != nil {
 		h.logger.Error("failed to calculate total cost", "operation", op, "error", err)
 		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to calculate total cost")
@@ -259,7 +416,113 @@ func (h *SubscriptionHandler) GetTotalCost(ctx *fiber.Ctx) error {
 		"service_name", req.ServiceName,
 		"start_date", *req.StartDate,
 		"end_date", *req.EndDate,
-		"total_cost", total,
+		"basis", *req.Basis,
+		"net_cost", total.Net,
+		"tax", total.Tax,
+		"gross_cost", total.Gross,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetCostBreakdown calculates monthly spend for a period
+// @Summary Get cost breakdown
+// @Description Calculate spend per calendar month for a specific period, optionally grouped by service or user
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "User ID filter (UUID), counts only the user's share of shared subscriptions"
+// @Param service_name query string false "Service name filter"
+// @Param tags query string false "Comma separated tag names, subscriptions labelled with any of them"
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param basis query string false "Cost basis: cash counts charges on their billing dates, accrual prorates them by day" default(cash) Enums(cash, accrual)
+// @Param currency query string false "ISO 4217 currency to convert amounts to" default(RUB)
+// @Param group_by query string false "Group each month by, a subscription is counted under each of its tags, without a payment method under none" Enums(service_name, user_id, tag, payment_method)
+// @Success 200 {object} dto.CostBreakdownHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/cost-breakdown [get]
+func (h *SubscriptionHandler) GetCostBreakdown(ctx *fiber.Ctx) error {
+	const op = "handler.GetCostBreakdown"
+
+	req, err := h.parser.ParseCostBreakdownRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse cost breakdown request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	months, err := h.usecase.GetCostBreakdown(ctx.Context(), usecase.CostFilter{
+		UserID:      req.UserID,
+		ServiceName: req.ServiceName,
+		Tags:        req.TagNames,
+		StartDate:   *req.StartDate,
+		EndDate:     *req.EndDate,
+		Basis:       entity.CostBasis(*req.Basis),
+		Currency:    *req.Currency,
+	}, entity.CostGroupBy(*req.GroupBy))
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		h.logger.Error("failed to convert cost breakdown", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "No exchange rate to "+*req.Currency+" for one of the subscriptions")
+	}
+	if err != nil {
+		h.logger.Error("failed to calculate cost breakdown", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to calculate cost breakdown")
+	}
+
+	response := h.mapper.ToCostBreakdownResponse(months, req)
+
+	h.logger.Info("cost breakdown calculated successfully",
+		"operation", op,
+		"user_id", req.UserID,
+		"service_name", req.ServiceName,
+		"start_date", *req.StartDate,
+		"end_date", *req.EndDate,
+		"basis", *req.Basis,
+		"group_by", *req.GroupBy,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+// Forecast projects spend for the upcoming months
+// @Summary Get spend forecast
+// @Description Project monthly spend of the currently running subscriptions, the current month is counted from today
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "User ID filter (UUID)"
+// @Param months query int false "Number of months to project" default(12) minimum(1) maximum(60)
+// @Param currency query string false "ISO 4217 currency to convert amounts to" default(RUB)
+// @Success 200 {object} dto.ForecastHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/forecast [get]
+func (h *SubscriptionHandler) Forecast(ctx *fiber.Ctx) error {
+	const op = "handler.Forecast"
+
+	req, err := h.parser.ParseForecastRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse forecast request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	months, err := h.usecase.Forecast(ctx.Context(), req.UserID, *req.Currency, *req.Months)
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		h.logger.Error("failed to convert forecast", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusUnprocessableEntity, "No exchange rate to "+*req.Currency+" for one of the subscriptions")
+	}
+	if err != nil {
+		h.logger.Error("failed to calculate forecast", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to calculate forecast")
+	}
+
+	response := h.mapper.ToForecastResponse(months, req)
+
+	h.logger.Info("forecast calculated successfully",
+		"operation", op,
+		"user_id", req.UserID,
+		"months", *req.Months,
 	)
 
 	return ctx.Status(fiber.StatusOK).JSON(response)
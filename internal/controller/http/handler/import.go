@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/controller/http/dto"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Import creates subscriptions from a CSV file
+// @Summary Import subscriptions
+// @Description Import subscriptions from a CSV file with a header row, each row validated as a new subscription. The valid rows are added in one transaction, the invalid and duplicate ones are skipped. A dry run only reports on the rows
+// @Tags subscriptions
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file with a header row, at most 1000 rows"
+// @Param mapping formData string false "JSON object of field to column name, e.g. {\"service_name\":\"Service\",\"price\":\"Cost\",\"attr.account_email\":\"Email\"}, default: the columns named as a field"
+// @Param date_format formData string false "Format of the dates, e.g. DD.MM.YYYY or MM/DD/YYYY, default: RFC3339"
+// @Param delimiter formData string false "Column delimiter" default(,)
+// @Param dry_run query bool false "Only validate the rows" default(false)
+// @Success 200 {object} dto.ImportSubscriptionsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/import [post]
+func (h *SubscriptionHandler) Import(ctx *fiber.Ctx) error {
+	const op = "handler.Import"
+
+	req, err := h.parser.ParseImportRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse import request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	response := dto.ImportSubscriptionsHandlerResponse{
+		DryRun: req.DryRun,
+		Rows:   make([]dto.ImportRowResult, len(req.Rows)),
+	}
+	subs := make([]*entity.Subscription, 0, len(req.Rows))
+	positions := make([]int, 0, len(req.Rows))
+	for i, row := range req.Rows {
+		response.Rows[i].Line = req.Lines[i]
+		if req.Errors[i] != "" {
+			response.Rows[i].Status = "invalid"
+			response.Rows[i].Error = req.Errors[i]
+			continue
+		}
+		sub, err := h.parser.ParseImportRow(ctx, req, row)
+		if err != nil {
+			response.Rows[i].Status = "invalid"
+			response.Rows[i].Error = err.Error()
+			continue
+		}
+		subs = append(subs, sub)
+		positions = append(positions, i)
+	}
+
+	outcomes, err := h.usecase.Import(ctx.Context(), subs, req.DryRun)
+	if err != nil {
+		h.logger.Error("failed to import subscriptions", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to import subscriptions")
+	}
+
+	for j, outcome := range outcomes {
+		row := &response.Rows[positions[j]]
+		switch {
+		case errors.Is(outcome, entity.ErrSubscriptionExists):
+			row.Status = "duplicate"
+			_, row.Error = subscriptionErrorStatus(outcome)
+		case outcome != nil:
+			row.Status = "invalid"
+			_, row.Error = subscriptionErrorStatus(outcome)
+		default:
+			row.Status = "valid"
+			if !req.DryRun {
+				row.ID = strconv.FormatInt(subs[j].Id, 10)
+			}
+		}
+	}
+
+	for _, row := range response.Rows {
+		switch row.Status {
+		case "valid":
+			response.Valid++
+		case "duplicate":
+			response.Duplicate++
+		default:
+			response.Invalid++
+		}
+	}
+
+	h.logger.Info("subscriptions imported",
+		"operation", op,
+		"dry_run", req.DryRun,
+		"valid", response.Valid,
+		"invalid", response.Invalid,
+		"duplicate", response.Duplicate,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
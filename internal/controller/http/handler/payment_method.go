@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StorePaymentMethod adds a payment method for a user
+// @Summary Create payment method
+// @Description Add a card, wallet or bank account a user pays subscriptions with, only the last four digits of its number are kept
+// @Tags payment methods
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body dto.PaymentMethodHandlerRequest true "Payment method data"
+// @Success 201 {object} dto.PaymentMethodItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/payment-methods [post]
+func (h *SubscriptionHandler) StorePaymentMethod(ctx *fiber.Ctx) error {
+	const op = "handler.StorePaymentMethod"
+
+	method, err := h.parser.ParseStorePaymentMethodRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse store payment method request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.StorePaymentMethod(ctx.Context(), method)
+	if err != nil {
+		h.logger.Error("failed to store payment method", "operation", op, "user_id", method.UserID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create payment method")
+	}
+
+	h.logger.Info("payment method created successfully",
+		"operation", op,
+		"payment_method_id", method.Id,
+		"user_id", method.UserID,
+	)
+
+	return ctx.Status(fiber.StatusCreated).JSON(h.mapper.ToPaymentMethodItem(method))
+}
+
+// ListPaymentMethods retrieves the payment methods of a user
+// @Summary List payment methods
+// @Description Get every payment method of a user
+// @Tags payment methods
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} dto.ListPaymentMethodsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/payment-methods [get]
+func (h *SubscriptionHandler) ListPaymentMethods(ctx *fiber.Ctx) error {
+	const op = "handler.ListPaymentMethods"
+
+	userID, err := h.parser.ParseUserRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse list payment methods request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	methods, err := h.usecase.ListPaymentMethods(ctx.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list payment methods", "operation", op, "user_id", userID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get payment methods")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToListPaymentMethodsResponse(methods))
+}
+
+// GetPaymentMethod retrieves a payment method by ID
+// @Summary Get payment method
+// @Description Get payment method of a user by ID
+// @Tags payment methods
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param id path int true "Payment method ID"
+// @Success 200 {object} dto.PaymentMethodItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/payment-methods/{id} [get]
+func (h *SubscriptionHandler) GetPaymentMethod(ctx *fiber.Ctx) error {
+	const op = "handler.GetPaymentMethod"
+
+	userID, id, err := h.parser.ParsePaymentMethodIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse get payment method request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	method, err := h.usecase.GetPaymentMethod(ctx.Context(), userID, id)
+	switch {
+	case errors.Is(err, entity.ErrPaymentMethodNotFound):
+		h.logger.Error("payment method not found", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Payment method not found")
+	case err != nil:
+		h.logger.Error("failed to get payment method", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get payment method")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToPaymentMethodItem(method))
+}
+
+// UpdatePaymentMethod updates a payment method
+// @Summary Update payment method
+// @Description Change the type, label or last four digits of a payment method
+// @Tags payment methods
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param id path int true "Payment method ID"
+// @Param request body dto.PaymentMethodHandlerRequest true "Payment method data"
+// @Success 200 {object} dto.PaymentMethodItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/payment-methods/{id} [put]
+func (h *SubscriptionHandler) UpdatePaymentMethod(ctx *fiber.Ctx) error {
+	const op = "handler.UpdatePaymentMethod"
+
+	method, err := h.parser.ParseUpdatePaymentMethodRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse update payment method request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.UpdatePaymentMethod(ctx.Context(), method)
+	switch {
+	case errors.Is(err, entity.ErrPaymentMethodNotFound):
+		h.logger.Error("payment method not found for update", "operation", op, "id", method.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Payment method not found")
+	case err != nil:
+		h.logger.Error("failed to update payment method", "operation", op, "id", method.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update payment method")
+	}
+
+	h.logger.Info("payment method updated successfully",
+		"operation", op,
+		"payment_method_id", method.Id,
+		"user_id", method.UserID,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToPaymentMethodItem(method))
+}
+
+// DeletePaymentMethod deletes a payment method
+// @Summary Delete payment method
+// @Description Delete a payment method and list the subscriptions that were paid with it and are now left without one
+// @Tags payment methods
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param id path int true "Payment method ID"
+// @Success 200 {object} dto.PaymentMethodSubscriptionsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/payment-methods/{id} [delete]
+func (h *SubscriptionHandler) DeletePaymentMethod(ctx *fiber.Ctx) error {
+	const op = "handler.DeletePaymentMethod"
+
+	userID, id, err := h.parser.ParsePaymentMethodIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse delete payment method request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	orphaned, err := h.usecase.DeletePaymentMethod(ctx.Context(), userID, id)
+	switch {
+	case errors.Is(err, entity.ErrPaymentMethodNotFound):
+		h.logger.Error("payment method not found for deletion", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Payment method not found")
+	case err != nil:
+		h.logger.Error("failed to delete payment method", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete payment method")
+	}
+
+	h.logger.Info("payment method deleted successfully",
+		"operation", op,
+		"payment_method_id", id,
+		"user_id", userID,
+		"orphaned_subscriptions", len(orphaned),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToPaymentMethodSubscriptionsResponse(orphaned))
+}
+
+// ListPaymentMethodSubscriptions retrieves the subscriptions paid with a payment method
+// @Summary List payment method subscriptions
+// @Description Get the subscriptions paid with a payment method, which would be left without one if it was deleted
+// @Tags payment methods
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param id path int true "Payment method ID"
+// @Success 200 {object} dto.PaymentMethodSubscriptionsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{user_id}/payment-methods/{id}/subscriptions [get]
+func (h *SubscriptionHandler) ListPaymentMethodSubscriptions(ctx *fiber.Ctx) error {
+	const op = "handler.ListPaymentMethodSubscriptions"
+
+	userID, id, err := h.parser.ParsePaymentMethodIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse payment method subscriptions request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	subscriptions, err := h.usecase.ListPaymentMethodSubscriptions(ctx.Context(), userID, id)
+	switch {
+	case errors.Is(err, entity.ErrPaymentMethodNotFound):
+		h.logger.Error("payment method not found", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Payment method not found")
+	case err != nil:
+		h.logger.Error("failed to list payment method subscriptions", "operation", op, "user_id", userID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get payment method subscriptions")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToPaymentMethodSubscriptionsResponse(subscriptions))
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StorePlan adds a plan to a service
+// @Summary Create plan
+// @Description Add a plan with its list price to a catalog service
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param request body dto.PlanHandlerRequest true "Plan data"
+// @Success 201 {object} dto.PlanItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id}/plans [post]
+func (h *SubscriptionHandler) StorePlan(ctx *fiber.Ctx) error {
+	const op = "handler.StorePlan"
+
+	plan, err := h.parser.ParseStorePlanRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse store plan request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.StorePlan(ctx.Context(), plan)
+	switch {
+	case errors.Is(err, entity.ErrServiceNotFound):
+		h.logger.Error("service not found for plan", "operation", op, "service_id", plan.ServiceID, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Service not found")
+	case errors.Is(err, entity.ErrPlanExists):
+		h.logger.Error("duplicate plan", "operation", op, "service_id", plan.ServiceID, "name", plan.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Plan with this name already exists")
+	case err != nil:
+		h.logger.Error("failed to store plan", "operation", op, "service_id", plan.ServiceID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create plan")
+	}
+
+	h.logger.Info("plan created successfully",
+		"operation", op,
+		"plan_id", plan.Id,
+		"service_id", plan.ServiceID,
+	)
+
+	return ctx.Status(fiber.StatusCreated).JSON(h.mapper.ToPlanItem(plan))
+}
+
+// ListPlans retrieves the plans of a service
+// @Summary List plans
+// @Description Get the plans of a catalog service ordered by price
+// @Tags services
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} dto.ListPlansHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id}/plans [get]
+func (h *SubscriptionHandler) ListPlans(ctx *fiber.Ctx) error {
+	const op = "handler.ListPlans"
+
+	serviceID, err := h.parser.ParseServiceIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse list plans request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	plans, err := h.usecase.ListPlans(ctx.Context(), serviceID)
+	switch {
+	case errors.Is(err, entity.ErrServiceNotFound):
+		h.logger.Error("service not found for plans", "operation", op, "service_id", serviceID, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Service not found")
+	case err != nil:
+		h.logger.Error("failed to list plans", "operation", op, "service_id", serviceID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get plans")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToListPlansResponse(plans))
+}
+
+// GetPlan retrieves a plan by ID
+// @Summary Get plan
+// @Description Get plan of a service by ID
+// @Tags services
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param plan_id path int true "Plan ID"
+// @Success 200 {object} dto.PlanItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id}/plans/{plan_id} [get]
+func (h *SubscriptionHandler) GetPlan(ctx *fiber.Ctx) error {
+	const op = "handler.GetPlan"
+
+	serviceID, id, err := h.parser.ParsePlanIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse get plan request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	plan, err := h.usecase.GetPlan(ctx.Context(), serviceID, id)
+	switch {
+	case errors.Is(err, entity.ErrPlanNotFound):
+		h.logger.Error("plan not found", "operation", op, "service_id", serviceID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Plan not found")
+	case err != nil:
+		h.logger.Error("failed to get plan", "operation", op, "service_id", serviceID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get plan")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToPlanItem(plan))
+}
+
+// UpdatePlan updates a plan
+// @Summary Update plan
+// @Description Change a plan, subscriptions already on it keep their price
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param plan_id path int true "Plan ID"
+// @Param request body dto.PlanHandlerRequest true "Plan data"
+// @Success 200 {object} dto.PlanItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id}/plans/{plan_id} [put]
+func (h *SubscriptionHandler) UpdatePlan(ctx *fiber.Ctx) error {
+	const op = "handler.UpdatePlan"
+
+	plan, err := h.parser.ParseUpdatePlanRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse update plan request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.UpdatePlan(ctx.Context(), plan)
+	switch {
+	case errors.Is(err, entity.ErrPlanNotFound):
+		h.logger.Error("plan not found for update", "operation", op, "id", plan.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Plan not found")
+	case errors.Is(err, entity.ErrPlanExists):
+		h.logger.Error("duplicate plan", "operation", op, "id", plan.Id, "name", plan.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Plan with this name already exists")
+	case err != nil:
+		h.logger.Error("failed to update plan", "operation", op, "id", plan.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update plan")
+	}
+
+	h.logger.Info("plan updated successfully",
+		"operation", op,
+		"plan_id", plan.Id,
+		"service_id", plan.ServiceID,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToPlanItem(plan))
+}
+
+// DeletePlan deletes a plan
+// @Summary Delete plan
+// @Description Delete a plan, the subscriptions on it keep their price
+// @Tags services
+// @Param id path int true "Service ID"
+// @Param plan_id path int true "Plan ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id}/plans/{plan_id} [delete]
+func (h *SubscriptionHandler) DeletePlan(ctx *fiber.Ctx) error {
+	const op = "handler.DeletePlan"
+
+	serviceID, id, err := h.parser.ParsePlanIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse delete plan request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.DeletePlan(ctx.Context(), serviceID, id)
+	switch {
+	case errors.Is(err, entity.ErrPlanNotFound):
+		h.logger.Error("plan not found for deletion", "operation", op, "service_id", serviceID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Plan not found")
+	case err != nil:
+		h.logger.Error("failed to delete plan", "operation", op, "service_id", serviceID, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete plan")
+	}
+
+	h.logger.Info("plan deleted successfully",
+		"operation", op,
+		"plan_id", id,
+		"service_id", serviceID,
+	)
+
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// PlanUsage reports how many users are on each plan
+// @Summary Plan usage
+// @Description Count the subscriptions active on every plan on the given date and the users holding them
+// @Tags services
+// @Produce json
+// @Param service_id query int false "Only the plans of this service"
+// @Param date query string false "Date (YYYY-MM-DD), default: today"
+// @Success 200 {object} dto.PlanUsageHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/plan-usage [get]
+func (h *SubscriptionHandler) PlanUsage(ctx *fiber.Ctx) error {
+	const op = "handler.PlanUsage"
+
+	serviceID, date, err := h.parser.ParsePlanUsageRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse plan usage request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	usage, err := h.usecase.PlanUsage(ctx.Context(), serviceID, date)
+	if err != nil {
+		h.logger.Error("failed to count plan usage", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to count plan usage")
+	}
+
+	h.logger.Info("plan usage counted successfully",
+		"operation", op,
+		"plans", len(usage),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToPlanUsageResponse(usage, date.Format("2006-01-02")))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StorePriceChange records a new subscription price
+// @Summary Add price change
+// @Description Record a new subscription price effective from the given date, earlier periods keep their price
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body dto.StorePriceChangeHandlerRequest true "Price change data"
+// @Success 201 {object} dto.PriceChangeItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/price-changes [post]
+func (h *SubscriptionHandler) StorePriceChange(ctx *fiber.Ctx) error {
+	const op = "handler.StorePriceChange"
+
+	change, err := h.parser.ParseStorePriceChangeRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse price change request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.AddPriceChange(ctx.Context(), change)
+	switch {
+	case errors.Is(err, entity.ErrSubscriptionNotFound):
+		h.logger.Error("subscription not found for price change", "operation", op, "id", change.SubscriptionID, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
+	case errors.Is(err, entity.ErrPriceChangeBeforeStart):
+		h.logger.Error("price change before subscription start", "operation", op, "id", change.SubscriptionID, "error", err)
+		return errorResponse(ctx, fiber.StatusBadRequest, "Effective date must not be before the subscription start date")
+	case errors.Is(err, entity.ErrPriceChangeExists):
+		h.logger.Error("duplicate price change", "operation", op, "id", change.SubscriptionID, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Price change with this effective date already exists")
+	case err != nil:
+		h.logger.Error("failed to store price change", "operation", op, "id", change.SubscriptionID, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create price change")
+	}
+
+	response := h.mapper.ToPriceChangeItem(change)
+
+	h.logger.Info("price change created successfully",
+		"operation", op,
+		"subscription_id", change.SubscriptionID,
+		"price_change_id", change.Id,
+	)
+
+	return ctx.Status(fiber.StatusCreated).JSON(response)
+}
+
+// ListPriceChanges retrieves the price history of a subscription
+// @Summary List price changes
+// @Description Get the price changes of a subscription ordered by effective date
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} dto.ListPriceChangesHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/price-changes [get]
+func (h *SubscriptionHandler) ListPriceChanges(ctx *fiber.Ctx) error {
+	const op = "handler.ListPriceChanges"
+
+	id, err := h.parser.ParseGetRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse list price changes request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	changes, err := h.usecase.ListPriceChanges(ctx.Context(), int64(id))
+	if err != nil {
+		h.logger.Error("failed to list price changes", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get price changes")
+	}
+
+	response := h.mapper.ToListPriceChangesResponse(changes)
+
+	h.logger.Info("price changes listed successfully",
+		"operation", op,
+		"subscription_id", id,
+		"count", len(changes),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
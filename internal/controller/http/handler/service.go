@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StoreService adds a service to the catalog
+// @Summary Create service
+// @Description Add a service to the catalog, its name is always one of its aliases
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param request body dto.ServiceHandlerRequest true "Service data"
+// @Success 201 {object} dto.ServiceItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services [post]
+func (h *SubscriptionHandler) StoreService(ctx *fiber.Ctx) error {
+	const op = "handler.StoreService"
+
+	service, err := h.parser.ParseStoreServiceRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse store service request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.StoreService(ctx.Context(), service)
+	switch {
+	case errors.Is(err, entity.ErrServiceExists):
+		h.logger.Error("duplicate service", "operation", op, "name", service.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Service with this name or alias already exists")
+	case err != nil:
+		h.logger.Error("failed to store service", "operation", op, "name", service.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create service")
+	}
+
+	h.logger.Info("service created successfully",
+		"operation", op,
+		"service_id", service.Id,
+		"name", service.Name,
+	)
+
+	return ctx.Status(fiber.StatusCreated).JSON(h.mapper.ToServiceItem(service))
+}
+
+// ListServices retrieves the services catalog
+// @Summary List services
+// @Description Get every service of the catalog with its aliases, ordered by name
+// @Tags services
+// @Produce json
+// @Success 200 {object} dto.ListServicesHandlerResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services [get]
+func (h *SubscriptionHandler) ListServices(ctx *fiber.Ctx) error {
+	const op = "handler.ListServices"
+
+	services, err := h.usecase.ListServices(ctx.Context())
+	if err != nil {
+		h.logger.Error("failed to list services", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get services")
+	}
+
+	h.logger.Info("services listed successfully",
+		"operation", op,
+		"count", len(services),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToListServicesResponse(services))
+}
+
+// GetService retrieves a service by ID
+// @Summary Get service
+// @Description Get service of the catalog by ID
+// @Tags services
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} dto.ServiceItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id} [get]
+func (h *SubscriptionHandler) GetService(ctx *fiber.Ctx) error {
+	const op = "handler.GetService"
+
+	id, err := h.parser.ParseServiceIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse get service request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	service, err := h.usecase.GetService(ctx.Context(), id)
+	switch {
+	case errors.Is(err, entity.ErrServiceNotFound):
+		h.logger.Error("service not found", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Service not found")
+	case err != nil:
+		h.logger.Error("failed to get service", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get service")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToServiceItem(service))
+}
+
+// UpdateService renames a service and replaces its aliases
+// @Summary Update service
+// @Description Rename a service and replace its aliases, its subscriptions show up under the new name
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param request body dto.ServiceHandlerRequest true "Service data"
+// @Success 200 {object} dto.ServiceItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id} [put]
+func (h *SubscriptionHandler) UpdateService(ctx *fiber.Ctx) error {
+	const op = "handler.UpdateService"
+
+	service, err := h.parser.ParseUpdateServiceRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse update service request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.UpdateService(ctx.Context(), service)
+	switch {
+	case errors.Is(err, entity.ErrServiceNotFound):
+		h.logger.Error("service not found for update", "operation", op, "id", service.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Service not found")
+	case errors.Is(err, entity.ErrServiceExists):
+		h.logger.Error("duplicate service", "operation", op, "id", service.Id, "name", service.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Service with this name or alias already exists")
+	case err != nil:
+		h.logger.Error("failed to update service", "operation", op, "id", service.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update service")
+	}
+
+	h.logger.Info("service updated successfully",
+		"operation", op,
+		"service_id", service.Id,
+		"name", service.Name,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToServiceItem(service))
+}
+
+// DeleteService removes a service from the catalog
+// @Summary Delete service
+// @Description Delete a service no subscription refers to, merge it into another one otherwise
+// @Tags services
+// @Param id path int true "Service ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id} [delete]
+func (h *SubscriptionHandler) DeleteService(ctx *fiber.Ctx) error {
+	const op = "handler.DeleteService"
+
+	id, err := h.parser.ParseServiceIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse delete service request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.DeleteService(ctx.Context(), id)
+	switch {
+	case errors.Is(err, entity.ErrServiceNotFound):
+		h.logger.Error("service not found for deletion", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Service not found")
+	case errors.Is(err, entity.ErrServiceInUse):
+		h.logger.Error("service in use", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Service has subscriptions, merge it into another service instead")
+	case err != nil:
+		h.logger.Error("failed to delete service", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete service")
+	}
+
+	h.logger.Info("service deleted successfully",
+		"operation", op,
+		"service_id", id,
+	)
+
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// MergeServices folds duplicate services into one
+// @Summary Merge services
+// @Description Move the subscriptions, plans and aliases of the duplicates to this service and delete the duplicates, whose names become aliases. Plans named like one of this service are replaced by the latter
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID to keep"
+// @Param request body dto.MergeServicesHandlerRequest true "Duplicate services"
+// @Success 200 {object} dto.ServiceItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /services/{id}/merge [post]
+func (h *SubscriptionHandler) MergeServices(ctx *fiber.Ctx) error {
+	const op = "handler.MergeServices"
+
+	id, duplicateIDs, err := h.parser.ParseMergeServicesRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse merge services request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	service, err := h.usecase.MergeServices(ctx.Context(), id, duplicateIDs)
+	switch {
+	case errors.Is(err, entity.ErrServiceNotFound):
+		h.logger.Error("service not found for merge", "operation", op, "id", id, "duplicate_ids", duplicateIDs, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Service not found")
+	case errors.Is(err, entity.ErrPlanExists):
+		h.logger.Error("conflicting plans", "operation", op, "id", id, "duplicate_ids", duplicateIDs, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Duplicates have plans with the same name, rename or delete one of them first")
+	case errors.Is(err, entity.ErrSubscriptionExists):
+		h.logger.Error("conflicting subscriptions", "operation", op, "id", id, "duplicate_ids", duplicateIDs, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "A user has subscriptions to two of the services starting on the same date, delete one of them first")
+	case err != nil:
+		h.logger.Error("failed to merge services", "operation", op, "id", id, "duplicate_ids", duplicateIDs, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to merge services")
+	}
+
+	h.logger.Info("services merged successfully",
+		"operation", op,
+		"service_id", id,
+		"duplicate_ids", duplicateIDs,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToServiceItem(service))
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReplaceShares sets the members a subscription is split across
+// @Summary Replace subscription shares
+// @Description Split a subscription across several users, each paying a weighted part or a fixed amount of every charge. The owner pays whatever the shares leave uncovered, an empty list leaves the whole price to the owner
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body dto.ReplaceSharesHandlerRequest true "Shares"
+// @Success 200 {object} dto.ListSharesHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/shares [put]
+func (h *SubscriptionHandler) ReplaceShares(ctx *fiber.Ctx) error {
+	const op = "handler.ReplaceShares"
+
+	id, shares, err := h.parser.ParseReplaceSharesRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse shares request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	shares, err = h.usecase.ReplaceShares(ctx.Context(), int64(id), shares)
+	switch {
+	case errors.Is(err, entity.ErrSubscriptionNotFound):
+		h.logger.Error("subscription not found for shares", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
+	case err != nil:
+		h.logger.Error("failed to replace shares", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update shares")
+	}
+
+	response := h.mapper.ToListSharesResponse(shares)
+
+	h.logger.Info("shares replaced successfully",
+		"operation", op,
+		"subscription_id", id,
+		"count", len(shares),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+// ListShares retrieves the members a subscription is split across
+// @Summary List subscription shares
+// @Description Get the users sharing a subscription besides its owner
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} dto.ListSharesHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/shares [get]
+func (h *SubscriptionHandler) ListShares(ctx *fiber.Ctx) error {
+	const op = "handler.ListShares"
+
+	id, err := h.parser.ParseGetRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse list shares request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	shares, err := h.usecase.ListShares(ctx.Context(), int64(id))
+	if err != nil {
+		h.logger.Error("failed to list shares", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get shares")
+	}
+
+	response := h.mapper.ToListSharesResponse(shares)
+
+	h.logger.Info("shares listed successfully",
+		"operation", op,
+		"subscription_id", id,
+		"count", len(shares),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Pause pauses a subscription
+// @Summary Pause subscription
+// @Description Pause an active or trial subscription, it is not charged until resumed
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} dto.GetSubscriptionHandlerResponse
+// @Header 200 {string} ETag "Version of the subscription, to send back in If-Match"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/pause [post]
+func (h *SubscriptionHandler) Pause(ctx *fiber.Ctx) error {
+	return h.changeStatus(ctx, "handler.Pause", h.usecase.Pause)
+}
+
+// Resume resumes a paused subscription
+// @Summary Resume subscription
+// @Description Resume a paused subscription
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} dto.GetSubscriptionHandlerResponse
+// @Header 200 {string} ETag "Version of the subscription, to send back in If-Match"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/resume [post]
+func (h *SubscriptionHandler) Resume(ctx *fiber.Ctx) error {
+	return h.changeStatus(ctx, "handler.Resume", h.usecase.Resume)
+}
+
+// Cancel cancels a subscription
+// @Summary Cancel subscription
+// @Description Cancel a subscription, it ends now unless it was set to end earlier
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} dto.GetSubscriptionHandlerResponse
+// @Header 200 {string} ETag "Version of the subscription, to send back in If-Match"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) Cancel(ctx *fiber.Ctx) error {
+	return h.changeStatus(ctx, "handler.Cancel", h.usecase.Cancel)
+}
+
+func (h *SubscriptionHandler) changeStatus(
+	ctx *fiber.Ctx,
+	op string,
+	transition func(ctx context.Context, id int, at time.Time) (*entity.Subscription, error),
+) error {
+	id, err := h.parser.ParseGetRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse status change request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	sub, err := transition(ctx.Context(), id, time.Now())
+	switch {
+	case errors.Is(err, entity.ErrSubscriptionNotFound):
+		h.logger.Error("subscription not found for status change", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
+	case errors.Is(err, entity.ErrInvalidStatusTransition):
+		h.logger.Error("invalid status transition", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Subscription status does not allow this change")
+	case errors.Is(err, entity.ErrSubscriptionNotStarted):
+		h.logger.Error("subscription not started for status change", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Subscription has not started yet, delete it instead")
+	case err != nil:
+		h.logger.Error("failed to change subscription status", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to change subscription status")
+	}
+
+	response := h.mapper.ToGetResponse(sub)
+	ctx.Set(fiber.HeaderETag, h.mapper.ToETag(sub))
+
+	h.logger.Info("subscription status changed successfully",
+		"operation", op,
+		"subscription_id", sub.Id,
+		"status", sub.Status,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
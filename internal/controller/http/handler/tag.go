@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StoreTag creates a new tag
+// @Summary Create tag
+// @Description Create a tag to label subscriptions with, names are lowercased
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body dto.StoreTagHandlerRequest true "Tag data"
+// @Success 201 {object} dto.TagItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tags [post]
+func (h *SubscriptionHandler) StoreTag(ctx *fiber.Ctx) error {
+	const op = "handler.StoreTag"
+
+	tag, err := h.parser.ParseStoreTagRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse store tag request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.StoreTag(ctx.Context(), tag)
+	switch {
+	case errors.Is(err, entity.ErrTagExists):
+		h.logger.Error("duplicate tag", "operation", op, "name", tag.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Tag with this name already exists")
+	case err != nil:
+		h.logger.Error("failed to store tag", "operation", op, "name", tag.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to create tag")
+	}
+
+	h.logger.Info("tag created successfully",
+		"operation", op,
+		"tag_id", tag.Id,
+		"name", tag.Name,
+	)
+
+	return ctx.Status(fiber.StatusCreated).JSON(h.mapper.ToTagItem(tag))
+}
+
+// ListTags retrieves every tag
+// @Summary List tags
+// @Description Get every tag ordered by name
+// @Tags tags
+// @Produce json
+// @Success 200 {object} dto.ListTagsHandlerResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tags [get]
+func (h *SubscriptionHandler) ListTags(ctx *fiber.Ctx) error {
+	const op = "handler.ListTags"
+
+	tags, err := h.usecase.ListTags(ctx.Context())
+	if err != nil {
+		h.logger.Error("failed to list tags", "operation", op, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get tags")
+	}
+
+	h.logger.Info("tags listed successfully",
+		"operation", op,
+		"count", len(tags),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToListTagsResponse(tags))
+}
+
+// GetTag retrieves a tag by ID
+// @Summary Get tag
+// @Description Get tag by ID
+// @Tags tags
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} dto.TagItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tags/{id} [get]
+func (h *SubscriptionHandler) GetTag(ctx *fiber.Ctx) error {
+	const op = "handler.GetTag"
+
+	id, err := h.parser.ParseTagIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse get tag request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	tag, err := h.usecase.GetTag(ctx.Context(), id)
+	switch {
+	case errors.Is(err, entity.ErrTagNotFound):
+		h.logger.Error("tag not found", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Tag not found")
+	case err != nil:
+		h.logger.Error("failed to get tag", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to get tag")
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToTagItem(tag))
+}
+
+// UpdateTag renames a tag
+// @Summary Update tag
+// @Description Rename a tag, the subscriptions it labels keep it
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param request body dto.UpdateTagHandlerRequest true "Tag data"
+// @Success 200 {object} dto.TagItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tags/{id} [put]
+func (h *SubscriptionHandler) UpdateTag(ctx *fiber.Ctx) error {
+	const op = "handler.UpdateTag"
+
+	tag, err := h.parser.ParseUpdateTagRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse update tag request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.UpdateTag(ctx.Context(), tag)
+	switch {
+	case errors.Is(err, entity.ErrTagNotFound):
+		h.logger.Error("tag not found for update", "operation", op, "id", tag.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Tag not found")
+	case errors.Is(err, entity.ErrTagExists):
+		h.logger.Error("duplicate tag", "operation", op, "id", tag.Id, "name", tag.Name, "error", err)
+		return errorResponse(ctx, fiber.StatusConflict, "Tag with this name already exists")
+	case err != nil:
+		h.logger.Error("failed to update tag", "operation", op, "id", tag.Id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update tag")
+	}
+
+	h.logger.Info("tag updated successfully",
+		"operation", op,
+		"tag_id", tag.Id,
+		"name", tag.Name,
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToTagItem(tag))
+}
+
+// DeleteTag deletes a tag
+// @Summary Delete tag
+// @Description Delete a tag and remove it from every subscription
+// @Tags tags
+// @Param id path int true "Tag ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tags/{id} [delete]
+func (h *SubscriptionHandler) DeleteTag(ctx *fiber.Ctx) error {
+	const op = "handler.DeleteTag"
+
+	id, err := h.parser.ParseTagIDRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse delete tag request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	err = h.usecase.DeleteTag(ctx.Context(), id)
+	switch {
+	case errors.Is(err, entity.ErrTagNotFound):
+		h.logger.Error("tag not found for deletion", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Tag not found")
+	case err != nil:
+		h.logger.Error("failed to delete tag", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to delete tag")
+	}
+
+	h.logger.Info("tag deleted successfully",
+		"operation", op,
+		"tag_id", id,
+	)
+
+	return ctx.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// ReplaceSubscriptionTags sets the tags of a subscription
+// @Summary Replace subscription tags
+// @Description Label a subscription with the given tags only, tags that do not exist yet are created
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body dto.ReplaceSubscriptionTagsHandlerRequest true "Tag names"
+// @Success 200 {object} dto.ListTagsHandlerResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /subscriptions/{id}/tags [put]
+func (h *SubscriptionHandler) ReplaceSubscriptionTags(ctx *fiber.Ctx) error {
+	const op = "handler.ReplaceSubscriptionTags"
+
+	id, names, err := h.parser.ParseReplaceSubscriptionTagsRequest(ctx)
+	if err != nil {
+		h.logger.Error("failed to parse subscription tags request", "operation", op, "error", err)
+		return errorResponse(ctx, err.(*fiber.Error).Code, err.Error())
+	}
+
+	tags, err := h.usecase.ReplaceSubscriptionTags(ctx.Context(), int64(id), names)
+	switch {
+	case errors.Is(err, entity.ErrSubscriptionNotFound):
+		h.logger.Error("subscription not found for tags", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusNotFound, "Subscription not found")
+	case err != nil:
+		h.logger.Error("failed to replace subscription tags", "operation", op, "id", id, "error", err)
+		return errorResponse(ctx, fiber.StatusInternalServerError, "Failed to update subscription tags")
+	}
+
+	h.logger.Info("subscription tags replaced successfully",
+		"operation", op,
+		"subscription_id", id,
+		"count", len(tags),
+	)
+
+	return ctx.Status(fiber.StatusOK).JSON(h.mapper.ToListTagsResponse(tags))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/controller/http/dto"
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/google/uuid"
 )
 
 type SubscriptionMapper struct{}
@@ -22,21 +23,63 @@ func (m *SubscriptionMapper) ToStoreResponse(sub *entity.Subscription) dto.Store
 
 func (m *SubscriptionMapper) ToGetResponse(sub *entity.Subscription) dto.GetSubscriptionHandlerResponse {
 	response := dto.GetSubscriptionHandlerResponse{
-		ServiceName: sub.ServiceName,
-		Price:       strconv.FormatUint(sub.Price, 10),
-		UserId:      sub.UserID.String(),
-		StartDate:   sub.StartDate.Format(time.RFC3339),
+		ServiceID:     strconv.FormatInt(sub.ServiceID, 10),
+		ServiceName:   sub.ServiceName,
+		Price:         sub.Price.Format(sub.Currency),
+		Currency:      sub.Currency,
+		BillingPeriod: string(sub.BillingPeriod),
+		Status:        string(sub.StatusAt(time.Now())),
+		UserId:        sub.UserID.String(),
+		StartDate:     sub.StartDate.Format(time.RFC3339),
+		TaxRegion:     sub.TaxRegion,
+		TaxInclusive:  strconv.FormatBool(sub.TaxInclusive),
+		Tags:          sub.TagNames(),
+	}
+
+	if sub.PlanID != 0 {
+		response.PlanID = strconv.FormatInt(sub.PlanID, 10)
+	}
+
+	if sub.PaymentMethodID != 0 {
+		response.PaymentMethodID = strconv.FormatInt(sub.PaymentMethodID, 10)
+	}
+
+	if sub.BillingPeriodDays > 0 {
+		response.BillingPeriodDays = strconv.Itoa(sub.BillingPeriodDays)
 	}
 
 	if !sub.EndDate.IsZero() {
 		response.EndDate = sub.EndDate.Format(time.RFC3339)
 	}
 
+	if !sub.TrialEndDate.IsZero() {
+		response.TrialStartDate = sub.TrialStartDate.Format(time.RFC3339)
+		response.TrialEndDate = sub.TrialEndDate.Format(time.RFC3339)
+	}
+
+	response.NextBillingDate, response.RemainingCharges = m.toBillingSchedule(sub)
+
+	response.Notes = sub.Notes
+	response.ETag = m.ToETag(sub)
+	response.Attributes = sub.Attributes
+	if response.Attributes == nil {
+		response.Attributes = map[string]string{}
+	}
+
+	response.AutoRenew = strconv.FormatBool(sub.AutoRenew)
+	response.CancellationNoticeDays = strconv.Itoa(sub.CancellationNoticeDays)
+	if deadline, ok := sub.CancellationDeadline(time.Now()); ok {
+		response.CancellationDeadline = deadline.Format("2006-01-02")
+	}
+
 	return response
 }
 
+// ToListResponse maps a page of subscriptions, userID is the user the list
+// is filtered by, if any, whose share of the current price is included.
 func (m *SubscriptionMapper) ToListResponse(
 	subscriptions []*entity.Subscription,
+	userID *uuid.UUID,
 	total int,
 	page int,
 	pageSize int,
@@ -50,40 +93,447 @@ func (m *SubscriptionMapper) ToListResponse(
 	}
 
 	for i, sub := range subscriptions {
-		item := dto.SubscriptionItem{
-			ID:          strconv.FormatInt(sub.Id, 10),
-			ServiceName: sub.ServiceName,
-			Price:       strconv.FormatUint(sub.Price, 10),
-			UserID:      sub.UserID.String(),
-			StartDate:   sub.StartDate.Format(time.RFC3339),
+		response.Subscriptions[i] = m.toSubscriptionItem(sub, userID)
+	}
+
+	return response
+}
+
+// toSubscriptionItem maps a subscription of a list, userID is the user
+// whose share of the current price is included, if any.
+func (m *SubscriptionMapper) toSubscriptionItem(sub *entity.Subscription, userID *uuid.UUID) dto.SubscriptionItem {
+	item := dto.SubscriptionItem{
+		ID:            strconv.FormatInt(sub.Id, 10),
+		ServiceID:     strconv.FormatInt(sub.ServiceID, 10),
+		ServiceName:   sub.ServiceName,
+		Price:         sub.Price.Format(sub.Currency),
+		Currency:      sub.Currency,
+		BillingPeriod: string(sub.BillingPeriod),
+		Status:        string(sub.StatusAt(time.Now())),
+		UserID:        sub.UserID.String(),
+		StartDate:     sub.StartDate.Format(time.RFC3339),
+		TaxRegion:     sub.TaxRegion,
+		TaxInclusive:  strconv.FormatBool(sub.TaxInclusive),
+		Tags:          sub.TagNames(),
+	}
+
+	if sub.PlanID != 0 {
+		item.PlanID = strconv.FormatInt(sub.PlanID, 10)
+	}
+
+	if sub.PaymentMethodID != 0 {
+		item.PaymentMethodID = strconv.FormatInt(sub.PaymentMethodID, 10)
+	}
+
+	if sub.BillingPeriodDays > 0 {
+		item.BillingPeriodDays = strconv.Itoa(sub.BillingPeriodDays)
+	}
+
+	if !sub.EndDate.IsZero() {
+		item.EndDate = sub.EndDate.Format(time.RFC3339)
+	}
+
+	if !sub.TrialEndDate.IsZero() {
+		item.TrialStartDate = sub.TrialStartDate.Format(time.RFC3339)
+		item.TrialEndDate = sub.TrialEndDate.Format(time.RFC3339)
+	}
+
+	item.NextBillingDate, item.RemainingCharges = m.toBillingSchedule(sub)
+
+	item.Notes = sub.Notes
+	item.ETag = m.ToETag(sub)
+	item.Attributes = sub.Attributes
+	if item.Attributes == nil {
+		item.Attributes = map[string]string{}
+	}
+
+	item.AutoRenew = strconv.FormatBool(sub.AutoRenew)
+	item.CancellationNoticeDays = strconv.Itoa(sub.CancellationNoticeDays)
+	if deadline, ok := sub.CancellationDeadline(time.Now()); ok {
+		item.CancellationDeadline = deadline.Format("2006-01-02")
+	}
+
+	if userID != nil {
+		price := sub.PriceOn(time.Now()).Rat(sub.Currency)
+		item.UserShare = entity.RoundMoney(sub.ShareOf(*userID, price), sub.Currency).Format(sub.Currency)
+	}
+
+	return item
+}
+
+// toBillingSchedule formats the next billing date and the number of charges
+// left, either is empty when there is none or no end to them.
+func (m *SubscriptionMapper) toBillingSchedule(sub *entity.Subscription) (string, string) {
+	now := time.Now()
+
+	var next, remaining string
+	if date, ok := sub.NextBillingDate(now); ok {
+		next = date.Format(time.RFC3339)
+	}
+	if count, ok := sub.RemainingCharges(now); ok {
+		remaining = strconv.Itoa(count)
+	}
+
+	return next, remaining
+}
+
+// ToETag renders the version of a subscription as a strong entity tag.
+func (m *SubscriptionMapper) ToETag(sub *entity.Subscription) string {
+	return `"` + strconv.FormatInt(sub.Version, 10) + `"`
+}
+
+func (m *SubscriptionMapper) ToUpdateResponse(sub *entity.Subscription) dto.GetSubscriptionHandlerResponse {
+	return m.ToGetResponse(sub)
+}
+
+func (m *SubscriptionMapper) ToTotalCostResponse(total *entity.TotalCost, req *dto.TotalCostHandlerRequest) dto.TotalCostHandlerResponse {
+	response := dto.TotalCostHandlerResponse{
+		NetCost:          total.Net.Format(*req.Currency),
+		Tax:              total.Tax.Format(*req.Currency),
+		GrossCost:        total.Gross.Format(*req.Currency),
+		UndiscountedCost: total.Undiscounted.Format(*req.Currency),
+		Currency:         *req.Currency,
+		Basis:            *req.Basis,
+		Period: dto.Period{
+			StartDate: *req.StartDate,
+			EndDate:   *req.EndDate,
+		},
+		Filters: dto.TotalCostFilters{
+			UserID:      req.UserID,
+			ServiceName: req.ServiceName,
+			Tags:        req.TagNames,
+		},
+		GroupBy: *req.GroupBy,
+		Groups:  m.toGroupCosts(total.Groups, *req.Currency),
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToCostBreakdownResponse(months []entity.MonthlyCost, req *dto.CostBreakdownHandlerRequest) dto.CostBreakdownHandlerResponse {
+	response := dto.CostBreakdownHandlerResponse{
+		Currency: *req.Currency,
+		Basis:    *req.Basis,
+		GroupBy:  *req.GroupBy,
+		Period: dto.Period{
+			StartDate: *req.StartDate,
+			EndDate:   *req.EndDate,
+		},
+		Filters: dto.TotalCostFilters{
+			UserID:      req.UserID,
+			ServiceName: req.ServiceName,
+			Tags:        req.TagNames,
+		},
+		Months: make([]dto.MonthlyCost, len(months)),
+	}
+
+	for i, month := range months {
+		response.Months[i] = m.toMonthlyCost(month, *req.Currency)
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToForecastResponse(months []entity.MonthlyCost, req *dto.ForecastHandlerRequest) dto.ForecastHandlerResponse {
+	response := dto.ForecastHandlerResponse{
+		Currency: *req.Currency,
+		Filters: dto.TotalCostFilters{
+			UserID: req.UserID,
+		},
+		Months: make([]dto.MonthlyCost, len(months)),
+	}
+
+	for i, month := range months {
+		response.Months[i] = m.toMonthlyCost(month, *req.Currency)
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) toMonthlyCost(month entity.MonthlyCost, currency string) dto.MonthlyCost {
+	return dto.MonthlyCost{
+		Month:     month.Month.Format("2006-01"),
+		TotalCost: month.Total.Format(currency),
+		Groups:    m.toGroupCosts(month.Groups, currency),
+	}
+}
+
+func (m *SubscriptionMapper) toGroupCosts(groups []entity.GroupCost, currency string) []dto.GroupCost {
+	var items []dto.GroupCost
+	for _, group := range groups {
+		items = append(items, dto.GroupCost{
+			Key:       group.Key,
+			TotalCost: group.Total.Format(currency),
+		})
+	}
+
+	return items
+}
+
+func (m *SubscriptionMapper) ToPriceChangeItem(change *entity.PriceChange) dto.PriceChangeItem {
+	return dto.PriceChangeItem{
+		ID:            strconv.FormatInt(change.Id, 10),
+		Price:         change.Price.Format(change.Currency),
+		EffectiveDate: change.EffectiveDate.Format(time.RFC3339),
+	}
+}
+
+func (m *SubscriptionMapper) ToListPriceChangesResponse(changes []entity.PriceChange) dto.ListPriceChangesHandlerResponse {
+	response := dto.ListPriceChangesHandlerResponse{
+		PriceChanges: make([]dto.PriceChangeItem, len(changes)),
+	}
+
+	for i := range changes {
+		response.PriceChanges[i] = m.ToPriceChangeItem(&changes[i])
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToDiscountItem(discount *entity.Discount) dto.DiscountItem {
+	item := dto.DiscountItem{
+		ID:    strconv.FormatInt(discount.Id, 10),
+		Kind:  string(discount.Kind),
+		Value: strconv.FormatUint(discount.Value, 10),
+	}
+
+	if discount.Kind == entity.DiscountFixed {
+		item.Value = entity.Money(discount.Value).Format(discount.Currency)
+	}
+
+	if !discount.StartDate.IsZero() {
+		item.StartDate = discount.StartDate.Format(time.RFC3339)
+	}
+
+	if !discount.EndDate.IsZero() {
+		item.EndDate = discount.EndDate.Format(time.RFC3339)
+	}
+
+	return item
+}
+
+func (m *SubscriptionMapper) ToListDiscountsResponse(discounts []entity.Discount) dto.ListDiscountsHandlerResponse {
+	response := dto.ListDiscountsHandlerResponse{
+		Discounts: make([]dto.DiscountItem, len(discounts)),
+	}
+
+	for i := range discounts {
+		response.Discounts[i] = m.ToDiscountItem(&discounts[i])
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToTagItem(tag *entity.Tag) dto.TagItem {
+	return dto.TagItem{
+		ID:   strconv.FormatInt(tag.Id, 10),
+		Name: tag.Name,
+	}
+}
+
+func (m *SubscriptionMapper) ToListTagsResponse(tags []entity.Tag) dto.ListTagsHandlerResponse {
+	response := dto.ListTagsHandlerResponse{
+		Tags: make([]dto.TagItem, len(tags)),
+	}
+
+	for i := range tags {
+		response.Tags[i] = m.ToTagItem(&tags[i])
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToServiceItem(service *entity.Service) dto.ServiceItem {
+	item := dto.ServiceItem{
+		ID:      strconv.FormatInt(service.Id, 10),
+		Name:    service.Name,
+		Aliases: service.Aliases,
+	}
+
+	if item.Aliases == nil {
+		item.Aliases = []string{}
+	}
+
+	return item
+}
+
+func (m *SubscriptionMapper) ToListServicesResponse(services []entity.Service) dto.ListServicesHandlerResponse {
+	response := dto.ListServicesHandlerResponse{
+		Services: make([]dto.ServiceItem, len(services)),
+	}
+
+	for i := range services {
+		response.Services[i] = m.ToServiceItem(&services[i])
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToPlanItem(plan *entity.Plan) dto.PlanItem {
+	item := dto.PlanItem{
+		ID:            strconv.FormatInt(plan.Id, 10),
+		ServiceID:     strconv.FormatInt(plan.ServiceID, 10),
+		Name:          plan.Name,
+		Price:         plan.Price.Format(plan.Currency),
+		Currency:      plan.Currency,
+		BillingPeriod: string(plan.BillingPeriod),
+	}
+
+	if plan.BillingPeriodDays > 0 {
+		item.BillingPeriodDays = strconv.Itoa(plan.BillingPeriodDays)
+	}
+
+	return item
+}
+
+func (m *SubscriptionMapper) ToListPlansResponse(plans []entity.Plan) dto.ListPlansHandlerResponse {
+	response := dto.ListPlansHandlerResponse{
+		Plans: make([]dto.PlanItem, len(plans)),
+	}
+
+	for i := range plans {
+		response.Plans[i] = m.ToPlanItem(&plans[i])
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToPlanUsageResponse(usage []entity.PlanUsage, date string) dto.PlanUsageHandlerResponse {
+	response := dto.PlanUsageHandlerResponse{
+		Date:  date,
+		Plans: make([]dto.PlanUsageItem, len(usage)),
+	}
+
+	for i := range usage {
+		response.Plans[i] = dto.PlanUsageItem{
+			Plan:          m.ToPlanItem(&usage[i].Plan),
+			ServiceName:   usage[i].ServiceName,
+			Users:         usage[i].Users,
+			Subscriptions: usage[i].Subscriptions,
+		}
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToPaymentMethodItem(method *entity.PaymentMethod) dto.PaymentMethodItem {
+	return dto.PaymentMethodItem{
+		ID:       strconv.FormatInt(method.Id, 10),
+		UserID:   method.UserID.String(),
+		Type:     string(method.Type),
+		Label:    method.Label,
+		LastFour: method.LastFour,
+		Masked:   method.Masked(),
+	}
+}
+
+func (m *SubscriptionMapper) ToListPaymentMethodsResponse(methods []entity.PaymentMethod) dto.ListPaymentMethodsHandlerResponse {
+	response := dto.ListPaymentMethodsHandlerResponse{
+		PaymentMethods: make([]dto.PaymentMethodItem, len(methods)),
+	}
+
+	for i := range methods {
+		response.PaymentMethods[i] = m.ToPaymentMethodItem(&methods[i])
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToPaymentMethodSubscriptionsResponse(subscriptions []*entity.Subscription) dto.PaymentMethodSubscriptionsHandlerResponse {
+	response := dto.PaymentMethodSubscriptionsHandlerResponse{
+		Subscriptions: make([]dto.SubscriptionItem, len(subscriptions)),
+	}
+
+	for i, sub := range subscriptions {
+		response.Subscriptions[i] = m.toSubscriptionItem(sub, nil)
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToBudgetItem(budget *entity.Budget) dto.BudgetItem {
+	return dto.BudgetItem{
+		ID:           strconv.FormatInt(budget.Id, 10),
+		UserID:       budget.UserID.String(),
+		MonthlyLimit: budget.MonthlyLimit.Format(budget.Currency),
+		Currency:     budget.Currency,
+		Tag:          budget.Tag,
+		ServiceName:  budget.ServiceName,
+	}
+}
+
+func (m *SubscriptionMapper) ToListBudgetsResponse(budgets []entity.Budget) dto.ListBudgetsHandlerResponse {
+	response := dto.ListBudgetsHandlerResponse{
+		Budgets: make([]dto.BudgetItem, len(budgets)),
+	}
+
+	for i := range budgets {
+		response.Budgets[i] = m.ToBudgetItem(&budgets[i])
+	}
+
+	return response
+}
+
+func (m *SubscriptionMapper) ToBudgetReportResponse(
+	userID uuid.UUID,
+	report []entity.BudgetUsage,
+	req *dto.BudgetReportHandlerRequest,
+) dto.BudgetReportHandlerResponse {
+	response := dto.BudgetReportHandlerResponse{
+		UserID:     userID.String(),
+		Basis:      *req.Basis,
+		StartMonth: *req.StartMonth,
+		EndMonth:   *req.EndMonth,
+		Budgets:    make([]dto.BudgetUsageItem, len(report)),
+	}
+
+	for i, usage := range report {
+		item := dto.BudgetUsageItem{
+			Budget:    m.ToBudgetItem(&usage.Budget),
+			Overspent: usage.Overspent(),
+			Months:    make([]dto.BudgetMonthItem, len(usage.Months)),
 		}
 
-		if !sub.EndDate.IsZero() {
-			item.EndDate = sub.EndDate.Format(time.RFC3339)
+		for j, month := range usage.Months {
+			var overspend entity.Money
+			if month.Overspent() {
+				overspend = month.Spent - month.Limit
+			}
+
+			item.Months[j] = dto.BudgetMonthItem{
+				Month:     month.Month.Format("2006-01"),
+				Limit:     month.Limit.Format(usage.Budget.Currency),
+				Spent:     month.Spent.Format(usage.Budget.Currency),
+				Overspent: month.Overspent(),
+				Overspend: overspend.Format(usage.Budget.Currency),
+			}
 		}
 
-		response.Subscriptions[i] = item
+		response.Overspent = response.Overspent || item.Overspent
+		response.Budgets[i] = item
 	}
 
 	return response
 }
 
-func (m *SubscriptionMapper) ToUpdateResponse(sub *entity.Subscription) dto.GetSubscriptionHandlerResponse {
-    return m.ToGetResponse(sub)
-}
-
-func (m *SubscriptionMapper) ToTotalCostResponse(total uint64, req *dto.TotalCostHandlerRequest) dto.TotalCostHandlerResponse {
-    response := dto.TotalCostHandlerResponse{
-        TotalCost: total,
-        Period: dto.Period{
-            StartDate: *req.StartDate,
-            EndDate:   *req.EndDate,
-        },
-        Filters: dto.TotalCostFilters{
-            UserID:      req.UserID,
-            ServiceName: req.ServiceName,
-        },
-    }
-    
-    return response
+func (m *SubscriptionMapper) ToListSharesResponse(shares []entity.Share) dto.ListSharesHandlerResponse {
+	response := dto.ListSharesHandlerResponse{
+		Shares: make([]dto.ShareItem, len(shares)),
+	}
+
+	for i, share := range shares {
+		item := dto.ShareItem{
+			UserID: share.UserID.String(),
+		}
+
+		if share.FixedAmount != nil {
+			item.FixedAmount = share.FixedAmount.Format(share.Currency)
+		} else {
+			item.Weight = strconv.FormatUint(share.Weight, 10)
+		}
+
+		response.Shares[i] = item
+	}
+
+	return response
 }
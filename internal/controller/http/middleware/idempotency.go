@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/controller/http/dto"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// IdempotencyKeyHeader carries the key a client retries a request with.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed for a retry.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore keeps the responses to requests made with an idempotency key.
+type IdempotencyStore interface {
+	BeginIdempotent(ctx context.Context, req *entity.IdempotentRequest) (*entity.IdempotentRequest, error)
+	CompleteIdempotent(ctx context.Context, req *entity.IdempotentRequest) error
+	ReleaseIdempotent(ctx context.Context, req *entity.IdempotentRequest) error
+}
+
+// Idempotency answers a retry of a request made with an Idempotency-Key with
+// the response to the first attempt for ttl, rather than serving it again.
+// The same key with another body is refused, a failed attempt releases it.
+func Idempotency(store IdempotencyStore, l logger.Interface, ttl time.Duration) func(c *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return ctx.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Error: "Idempotency-Key must not exceed 255 characters"})
+		}
+
+		hash := sha256.Sum256(ctx.Body())
+		req := &entity.IdempotentRequest{
+			Operation:   ctx.Method() + " " + ctx.Path(),
+			Key:         key,
+			RequestHash: hex.EncodeToString(hash[:]),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		stored, err := store.BeginIdempotent(ctx.Context(), req)
+		switch {
+		case errors.Is(err, entity.ErrIdempotencyKeyReused):
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(dto.ErrorResponse{Error: "Idempotency-Key was already used for a different request"})
+		case errors.Is(err, entity.ErrIdempotencyKeyInProgress):
+			return ctx.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Error: "A request with this Idempotency-Key is in progress"})
+		case err != nil:
+			l.Error("failed to reserve idempotency key", "key", key, "error", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Error: "Failed to process request"})
+		case stored != nil:
+			ctx.Set(IdempotentReplayedHeader, "true")
+			ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return ctx.Status(stored.StatusCode).Send(stored.ResponseBody)
+		}
+
+		// the key is released unless the response is stored, also when the
+		// handler panics, so that a retry is served again
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.ReleaseIdempotent(ctx.Context(), req); err != nil {
+				l.Error("failed to release idempotency key", "key", key, "error", err)
+			}
+		}()
+
+		if err := ctx.Next(); err != nil {
+			return err
+		}
+		if ctx.Response().StatusCode() >= fiber.StatusInternalServerError {
+			return nil
+		}
+
+		req.StatusCode = ctx.Response().StatusCode()
+		req.ResponseBody = append([]byte(nil), ctx.Response().Body()...)
+		if err := store.CompleteIdempotent(ctx.Context(), req); err != nil {
+			l.Error("failed to store idempotent response", "key", key, "error", err)
+			return nil
+		}
+		completed = true
+
+		return nil
+	}
+}
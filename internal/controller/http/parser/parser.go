@@ -1,23 +1,45 @@
 package parser
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/controller/http/dto"
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
 	"github.com/M1r0-dev/Subscription-Aggregator/pkg/logger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+// Catalog maps the service a subscription is for, as typed by a user, to
+// its entry in the services catalog and looks up the plans of the service,
+// along with the subscriptions whose currency amounts are given in.
+type Catalog interface {
+	FindService(ctx context.Context, name string) (*entity.Service, error)
+	GetPlan(ctx context.Context, serviceID, id int64) (*entity.Plan, error)
+	Get(ctx context.Context, id int) (*entity.Subscription, error)
+}
+
 type SubscriptionParser struct {
-	logger logger.Interface
+	logger  logger.Interface
+	catalog Catalog
 }
 
-func New(logger logger.Interface) *SubscriptionParser {
+func New(logger logger.Interface, catalog Catalog) *SubscriptionParser {
 	return &SubscriptionParser{
-		logger: logger,
+		logger:  logger,
+		catalog: catalog,
 	}
 }
 
@@ -27,10 +49,16 @@ func (p *SubscriptionParser) ParseStoreRequest(ctx *fiber.Ctx) (*entity.Subscrip
 		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid request body")
 	}
 
+	return p.parseSubscription(ctx, req)
+}
+
+// parseSubscription validates a subscription as a whole, for a new one as
+// well as for one that replaces or patches an existing one.
+func (p *SubscriptionParser) parseSubscription(ctx *fiber.Ctx, req dto.StoreSubscriptionHandlerRequest) (*entity.Subscription, error) {
 	if req.ServiceName == "" {
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Service name is required")
 	}
-	if req.Price == "" {
+	if req.Price == "" && req.PlanID == "" {
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Price is required")
 	}
 	if req.UserId == "" {
@@ -40,9 +68,8 @@ func (p *SubscriptionParser) ParseStoreRequest(ctx *fiber.Ctx) (*entity.Subscrip
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Start date is required")
 	}
 
-	price, err := strconv.ParseUint(req.Price, 10, 64)
-	if err != nil {
-		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid price format")
+	if !entity.ValidServiceName(entity.NormalizeServiceName(req.ServiceName)) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid service name, must be 1 to 255 characters")
 	}
 
 	userID, err := uuid.Parse(req.UserId)
@@ -65,62 +92,290 @@ func (p *SubscriptionParser) ParseStoreRequest(ctx *fiber.Ctx) (*entity.Subscrip
 	} else {
 		endDate = time.Time{}
 	}
+	if !endDate.IsZero() && !endDate.After(startDate) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "End date must be after start date")
+	}
 
-	return &entity.Subscription{
-		ServiceName: req.ServiceName,
-		Price:       price,
-		UserID:      userID,
-		StartDate:   startDate,
-		EndDate:     endDate,
-	}, nil
-}
+	trialStart, trialEnd, err := parseTrial(startDate, req.TrialStartDate, req.TrialEndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	taxRegion, taxInclusive, err := parseTaxProfile(req.TaxRegion, req.TaxInclusive, true)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentMethodID, err := parsePaymentMethodID(req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	autoRenew, noticeDays, err := parseRenewal(req.AutoRenew, req.CancellationNoticeDays, true, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !autoRenew && endDate.IsZero() {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "End date is required for a subscription that does not renew")
+	}
+
+	notes, err := parseNotes(req.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := parseAttributes(req.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	// the catalog is only looked at once all that does not depend on the plan is valid
+	service, err := p.findService(ctx, req.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan *entity.Plan
+	if req.PlanID != "" {
+		plan, err = p.resolvePlan(ctx, service, req.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		// what is not given is taken from the plan
+		if req.Price == "" {
+			req.Price = plan.Price.Format(plan.Currency)
+		}
+		if req.Currency == "" {
+			req.Currency = plan.Currency
+		}
+		if req.BillingPeriod == "" && req.BillingPeriodDays == "" {
+			req.BillingPeriod = string(plan.BillingPeriod)
+			if plan.BillingPeriodDays > 0 {
+				req.BillingPeriodDays = strconv.Itoa(plan.BillingPeriodDays)
+			}
+		}
+	}
+
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := parseMoney(req.Price, currency, "price")
+	if err != nil {
+		return nil, err
+	}
+
+	if req.BillingPeriod == "" {
+		req.BillingPeriod = string(entity.BillingMonthly)
+	}
+	period, periodDays, err := parseBillingPeriod(req.BillingPeriod, req.BillingPeriodDays)
+	if err != nil {
+		return nil, err
+	}
+
+	status := entity.StatusActive
+	if trialEnd.After(time.Now()) {
+		status = entity.StatusTrial
+	}
+
+	sub := &entity.Subscription{
+		ServiceID:              service.Id,
+		ServiceName:            service.Name,
+		Price:                  price,
+		Currency:               currency,
+		BillingPeriod:          period,
+		BillingPeriodDays:      periodDays,
+		Status:                 status,
+		UserID:                 userID,
+		StartDate:              startDate,
+		EndDate:                endDate,
+		TrialStartDate:         trialStart,
+		TrialEndDate:           trialEnd,
+		TaxRegion:              taxRegion,
+		TaxInclusive:           taxInclusive,
+		PaymentMethodID:        paymentMethodID,
+		AutoRenew:              autoRenew,
+		CancellationNoticeDays: noticeDays,
+		Notes:                  notes,
+		Attributes:             attributes,
+	}
+
+	if plan != nil {
+		sub.PlanID = plan.Id
+	}
 
+	return sub, nil
+}
 
+// ParseUpdateRequest replaces every field of existingSub a client controls,
+// what the request leaves out is reset to its default as on creation.
 func (p *SubscriptionParser) ParseUpdateRequest(ctx *fiber.Ctx, existingSub *entity.Subscription) error {
 	var req dto.UpdateSubscriptionHandlerRequest
 	if err := ctx.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if req.ServiceName != "" {
-		existingSub.ServiceName = req.ServiceName
+	sub, err := p.parseSubscription(ctx, dto.StoreSubscriptionHandlerRequest(req))
+	if err != nil {
+		return err
+	}
+	if err := existingSub.ReplaceWith(sub, time.Now()); err != nil {
+		return fiber.NewError(fiber.StatusConflict, "End date of a cancelled or expired subscription cannot be changed")
 	}
 
-	if req.Price != "" {
-		price, err := strconv.ParseUint(req.Price, 10, 64)
-		if err != nil {
-			return fiber.NewError(fiber.StatusBadRequest, "Invalid price format")
-		}
-		existingSub.Price = price
+	return nil
+}
+
+// MergePatchContentType is the media type of a JSON Merge Patch, RFC 7396.
+const MergePatchContentType = "application/merge-patch+json"
+
+// ParsePatchRequest applies a JSON Merge Patch to existingSub, a null
+// removes a value. The patched subscription is validated as a whole.
+func (p *SubscriptionParser) ParsePatchRequest(ctx *fiber.Ctx, existingSub *entity.Subscription) error {
+	mediaType, _, err := mime.ParseMediaType(ctx.Get(fiber.HeaderContentType))
+	if err != nil || mediaType != MergePatchContentType {
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, "Content-Type must be "+MergePatchContentType)
 	}
 
-	if req.UserId != "" {
-		userID, err := uuid.Parse(req.UserId)
-		if err != nil {
-			return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format, must be UUID")
-		}
-		existingSub.UserID = userID 
+	var patch map[string]any
+	if err := json.Unmarshal(ctx.Body(), &patch); err != nil || patch == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body, must be a JSON object")
 	}
 
-	if req.StartDate != "" {
-		startDate, err := time.Parse(time.RFC3339, req.StartDate)
-		if err != nil {
-			return fiber.NewError(fiber.StatusBadRequest, "Invalid start date format")
+	current, err := toJSONObject(subscriptionDocument(existingSub))
+	if err != nil {
+		p.logger.Error("failed to encode subscription", "subscription_id", existingSub.Id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to patch subscription")
+	}
+
+	if err := p.followPatchedPlan(ctx, current, patch); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(current, patch))
+	if err != nil {
+		p.logger.Error("failed to encode patched subscription", "subscription_id", existingSub.Id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to patch subscription")
+	}
+
+	var req dto.StoreSubscriptionHandlerRequest
+	if err := json.Unmarshal(merged, &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	sub, err := p.parseSubscription(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := existingSub.ReplaceWith(sub, time.Now()); err != nil {
+		return fiber.NewError(fiber.StatusConflict, "End date of a cancelled or expired subscription cannot be changed")
+	}
+
+	return nil
+}
+
+// followPatchedPlan drops from current what follows the plan the patch
+// switches to unless the patch sets it, and the plan of the service the
+// patch switches away from.
+func (p *SubscriptionParser) followPatchedPlan(ctx *fiber.Ctx, current, patch map[string]any) error {
+	if name, ok := patch["service_name"].(string); ok && name != "" {
+		if _, ok := patch["plan_id"]; !ok {
+			service, err := p.findService(ctx, name)
+			if err != nil {
+				return err
+			}
+			if current["service_name"] != service.Name {
+				delete(current, "plan_id")
+			}
 		}
-		existingSub.StartDate = startDate
 	}
 
-	if req.EndDate != "" {
-		endDate, err := time.Parse(time.RFC3339, req.EndDate)
-		if err != nil {
-			return fiber.NewError(fiber.StatusBadRequest, "Invalid end date format")
+	if planID, ok := patch["plan_id"].(string); ok && planID != "" && planID != current["plan_id"] {
+		_, pricePatched := patch["price"]
+		for _, key := range []string{"price", "currency", "billing_period", "billing_period_days"} {
+			if _, ok := patch[key]; ok || key == "currency" && pricePatched {
+				continue
+			}
+			delete(current, key)
 		}
-		existingSub.EndDate = endDate
 	}
 
 	return nil
 }
 
+// mergePatch applies patch to target as RFC 7396 describes.
+func mergePatch(target, patch map[string]any) map[string]any {
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]any:
+			nested, _ := target[key].(map[string]any)
+			if nested == nil {
+				nested = map[string]any{}
+			}
+			target[key] = mergePatch(nested, value)
+		default:
+			target[key] = value
+		}
+	}
+
+	return target
+}
+
+func toJSONObject(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// subscriptionDocument renders sub the way a client creates it, so that a
+// patch keeps whatever it leaves out.
+func subscriptionDocument(sub *entity.Subscription) dto.StoreSubscriptionHandlerRequest {
+	doc := dto.StoreSubscriptionHandlerRequest{
+		ServiceName:            sub.ServiceName,
+		Price:                  sub.Price.Format(sub.Currency),
+		Currency:               sub.Currency,
+		BillingPeriod:          string(sub.BillingPeriod),
+		UserId:                 sub.UserID.String(),
+		StartDate:              sub.StartDate.Format(time.RFC3339),
+		TaxRegion:              sub.TaxRegion,
+		TaxInclusive:           strconv.FormatBool(sub.TaxInclusive),
+		AutoRenew:              strconv.FormatBool(sub.AutoRenew),
+		CancellationNoticeDays: strconv.Itoa(sub.CancellationNoticeDays),
+		Notes:                  sub.Notes,
+		Attributes:             sub.Attributes,
+	}
+
+	if sub.PlanID != 0 {
+		doc.PlanID = strconv.FormatInt(sub.PlanID, 10)
+	}
+	if sub.BillingPeriodDays > 0 {
+		doc.BillingPeriodDays = strconv.Itoa(sub.BillingPeriodDays)
+	}
+	if !sub.EndDate.IsZero() {
+		doc.EndDate = sub.EndDate.Format(time.RFC3339)
+	}
+	if !sub.TrialEndDate.IsZero() {
+		doc.TrialStartDate = sub.TrialStartDate.Format(time.RFC3339)
+		doc.TrialEndDate = sub.TrialEndDate.Format(time.RFC3339)
+	}
+	if sub.PaymentMethodID != 0 {
+		doc.PaymentMethodID = strconv.FormatInt(sub.PaymentMethodID, 10)
+	}
+
+	return doc
+}
+
 func (p *SubscriptionParser) ParseListRequest(ctx *fiber.Ctx) (*dto.ListSubscriptionsHandlerRequest, error) {
 	var req dto.ListSubscriptionsHandlerRequest
 	if err := ctx.QueryParser(&req); err != nil {
@@ -153,9 +408,69 @@ func (p *SubscriptionParser) ParseListRequest(ctx *fiber.Ctx) (*dto.ListSubscrip
 		}
 	}
 
+	if req.TrialEndsWithinDays != nil && *req.TrialEndsWithinDays < 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Trial ends within days must not be negative")
+	}
+
+	if req.CancellationDeadlineWithinDays != nil && *req.CancellationDeadlineWithinDays < 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Cancellation deadline within days must not be negative")
+	}
+
+	if !listSortFields[req.SortBy] {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid sort field")
+	}
+	if req.SortOrder != "asc" && req.SortOrder != "desc" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Sort order must be asc or desc")
+	}
+
+	tags, err := parseTagFilter(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	req.TagNames = tags
+
+	attributes, err := parseAttributeFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Attributes = attributes
+
+	if req.NextBillingFrom != nil {
+		from, err := time.Parse("2006-01-02", *req.NextBillingFrom)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid next billing from format, use YYYY-MM-DD")
+		}
+		req.NextBillingFromDate = &from
+	}
+
+	if req.NextBillingTo != nil {
+		to, err := time.Parse("2006-01-02", *req.NextBillingTo)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid next billing to format, use YYYY-MM-DD")
+		}
+		// inclusive, charges fall at any time of the day
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		req.NextBillingToDate = &to
+	}
+
+	if req.NextBillingFromDate != nil && req.NextBillingToDate != nil && req.NextBillingToDate.Before(*req.NextBillingFromDate) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Next billing to must not be before next billing from")
+	}
+
 	return &req, nil
 }
 
+// listSortFields are the fields a list can be sorted by, all of them but
+// next_billing_date are subscription columns.
+var listSortFields = map[string]bool{
+	"start_date":        true,
+	"end_date":          true,
+	"created_at":        true,
+	"service_name":      true,
+	"price":             true,
+	"next_billing_date": true,
+}
+
 func (p *SubscriptionParser) ParseGetRequest(ctx *fiber.Ctx) (int, error) {
 	idStr := ctx.Params("id")
 	if idStr == "" {
@@ -170,35 +485,1427 @@ func (p *SubscriptionParser) ParseGetRequest(ctx *fiber.Ctx) (int, error) {
 	return id, nil
 }
 
+// ParseIfMatchRequest returns the subscription versions the If-Match header
+// allows a write at, none when it is missing or *. An entity tag that is no
+// version, a weak one among them, never matches.
+func (p *SubscriptionParser) ParseIfMatchRequest(ctx *fiber.Ctx) ([]int64, error) {
+	return parseIfMatch(ctx.Get(fiber.HeaderIfMatch))
+}
+
+func parseIfMatch(header string) ([]int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil || version <= 0 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, fiber.NewError(fiber.StatusPreconditionFailed, "Subscription was modified, fetch it again")
+	}
+
+	return versions, nil
+}
+
+// maxBatchOperations caps the operations of a batch.
+const maxBatchOperations = 100
+
+// ParseBatchRequest returns the operations of a batch and whether it is atomic.
+func (p *SubscriptionParser) ParseBatchRequest(ctx *fiber.Ctx) ([]dto.BatchOperation, bool, error) {
+	var req dto.BatchSubscriptionsHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, false, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if len(req.Operations) == 0 {
+		return nil, false, fiber.NewError(fiber.StatusBadRequest, "Operations are required")
+	}
+	if len(req.Operations) > maxBatchOperations {
+		return nil, false, fiber.NewError(fiber.StatusBadRequest, "A batch can hold at most 100 operations")
+	}
+
+	atomic := false
+	if raw := ctx.Query("atomic"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, false, fiber.NewError(fiber.StatusBadRequest, "Invalid atomic, must be true or false")
+		}
+		atomic = parsed
+	}
+
+	return req.Operations, atomic, nil
+}
+
+// ParseBatchOperation parses an operation of a batch like the request it
+// stands for, an update replaces the subscription as PUT does.
+func (p *SubscriptionParser) ParseBatchOperation(ctx *fiber.Ctx, op dto.BatchOperation) (usecase.BatchOp, error) {
+	batchOp := usecase.BatchOp{Kind: usecase.BatchOpKind(op.Op)}
+	switch batchOp.Kind {
+	case usecase.BatchCreate, usecase.BatchUpdate, usecase.BatchDelete:
+	default:
+		return batchOp, fiber.NewError(fiber.StatusBadRequest, "Invalid operation, must be create, update or delete")
+	}
+
+	if batchOp.Kind != usecase.BatchCreate {
+		if op.ID == "" {
+			return batchOp, fiber.NewError(fiber.StatusBadRequest, "Subscription ID is required")
+		}
+		id, err := strconv.Atoi(op.ID)
+		if err != nil {
+			return batchOp, fiber.NewError(fiber.StatusBadRequest, "Invalid subscription ID format")
+		}
+		versions, err := parseIfMatch(op.IfMatch)
+		if err != nil {
+			return batchOp, err
+		}
+		batchOp.ID = id
+		batchOp.Versions = versions
+	}
+
+	if batchOp.Kind != usecase.BatchDelete {
+		if op.Subscription == nil {
+			return batchOp, fiber.NewError(fiber.StatusBadRequest, "Subscription is required")
+		}
+		sub, err := p.parseSubscription(ctx, *op.Subscription)
+		if err != nil {
+			return batchOp, err
+		}
+		batchOp.Sub = sub
+	}
+
+	return batchOp, nil
+}
+
+// maxImportRows caps the rows of an imported CSV file.
+const maxImportRows = 1000
+
+// importFields sets the field of a subscription a CSV column is mapped to,
+// the attr.<key> fields aside.
+var importFields = map[string]func(req *dto.StoreSubscriptionHandlerRequest, value string){
+	"service_name":             func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.ServiceName = value },
+	"plan_id":                  func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.PlanID = value },
+	"price":                    func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.Price = value },
+	"currency":                 func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.Currency = value },
+	"billing_period":           func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.BillingPeriod = value },
+	"billing_period_days":      func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.BillingPeriodDays = value },
+	"user_id":                  func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.UserId = value },
+	"start_date":               func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.StartDate = value },
+	"end_date":                 func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.EndDate = value },
+	"trial_start_date":         func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.TrialStartDate = value },
+	"trial_end_date":           func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.TrialEndDate = value },
+	"tax_region":               func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.TaxRegion = value },
+	"tax_inclusive":            func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.TaxInclusive = value },
+	"payment_method_id":        func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.PaymentMethodID = value },
+	"auto_renew":               func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.AutoRenew = value },
+	"cancellation_notice_days": func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.CancellationNoticeDays = value },
+	"notes":                    func(req *dto.StoreSubscriptionHandlerRequest, value string) { req.Notes = value },
+}
+
+var (
+	dateFormatPattern = regexp.MustCompile(`^(YYYY|YY|MM|DD|[-./ ])+$`)
+	dateFormatLayout  = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02")
+)
+
+// ParseImportRequest reads the rows of an uploaded CSV file into requests to
+// create a subscription, mapping its columns to their fields.
+func (p *SubscriptionParser) ParseImportRequest(ctx *fiber.Ctx) (*dto.ImportSubscriptionsHandlerRequest, error) {
+	var req dto.ImportSubscriptionsHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body, must be multipart/form-data")
+	}
+
+	if raw := ctx.Query("dry_run"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid dry_run, must be true or false")
+		}
+		req.DryRun = dryRun
+	}
+
+	if req.DateFormat != "" {
+		if !dateFormatPattern.MatchString(req.DateFormat) ||
+			!strings.Contains(req.DateFormat, "YY") || !strings.Contains(req.DateFormat, "MM") || !strings.Contains(req.DateFormat, "DD") {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid date format, must be made of YYYY or YY, MM and DD, e.g. DD.MM.YYYY")
+		}
+		req.DateLayout = dateFormatLayout.Replace(req.DateFormat)
+	}
+
+	comma := ','
+	if req.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(req.Delimiter)
+		if size != len(req.Delimiter) || delimiter == utf8.RuneError || delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid delimiter, must be a single character")
+		}
+		comma = delimiter
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "CSV file is required")
+	}
+	file, err := header.Open()
+	if err != nil {
+		p.logger.Error("failed to open uploaded file", "error", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Failed to read CSV file")
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = comma
+	reader.TrimLeadingSpace = true
+	// a row with more or fewer columns than the header is reported on its own
+	reader.FieldsPerRecord = -1
+
+	names, err := reader.Read()
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "CSV file must start with a header row")
+	}
+	// spreadsheets save a byte order mark ahead of the first column
+	names[0] = strings.TrimPrefix(names[0], "\ufeff")
+
+	columns, err := parseImportMapping(req.Mapping, names)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid CSV file: "+err.Error())
+		}
+		if len(req.Rows) == maxImportRows {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "A CSV file can hold at most 1000 rows")
+		}
+
+		if parseErr != nil {
+			req.Rows = append(req.Rows, dto.StoreSubscriptionHandlerRequest{})
+			req.Lines = append(req.Lines, parseErr.StartLine)
+			req.Errors = append(req.Errors, "Invalid CSV row: "+parseErr.Err.Error())
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) < len(names) {
+			req.Rows = append(req.Rows, dto.StoreSubscriptionHandlerRequest{})
+			req.Lines = append(req.Lines, line)
+			req.Errors = append(req.Errors, "Row has "+strconv.Itoa(len(record))+" columns, the header has "+strconv.Itoa(len(names)))
+			continue
+		}
+
+		var row dto.StoreSubscriptionHandlerRequest
+		for field, column := range columns {
+			value := strings.TrimSpace(record[column])
+			if value == "" {
+				continue
+			}
+			if key, ok := strings.CutPrefix(field, attributePrefix); ok {
+				if row.Attributes == nil {
+					row.Attributes = map[string]string{}
+				}
+				row.Attributes[key] = value
+				continue
+			}
+			importFields[field](&row, value)
+		}
+
+		req.Rows = append(req.Rows, row)
+		req.Lines = append(req.Lines, line)
+		req.Errors = append(req.Errors, "")
+	}
+
+	if len(req.Rows) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "CSV file has no rows")
+	}
+
+	return &req, nil
+}
+
+// parseImportMapping returns the column each mapped field is read from. The
+// mapping is a JSON object of field to column name, without one the columns
+// named as a field are read.
+func parseImportMapping(mapping string, names []string) (map[string]int, error) {
+	indexes := make(map[string]int, len(names))
+	for i, name := range names {
+		indexes[strings.TrimSpace(name)] = i
+	}
+
+	fields := map[string]string{}
+	if mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &fields); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid mapping, must be a JSON object of field to column name")
+		}
+	} else {
+		for name := range indexes {
+			if _, ok := importFields[name]; ok || strings.HasPrefix(name, attributePrefix) {
+				fields[name] = name
+			}
+		}
+	}
+
+	columns := make(map[string]int, len(fields))
+	for field, name := range fields {
+		if _, ok := importFields[field]; !ok {
+			key, ok := strings.CutPrefix(field, attributePrefix)
+			if !ok || !entity.ValidAttributeKey(key) {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "Unknown field "+field+" in mapping")
+			}
+		}
+		column, ok := indexes[name]
+		if !ok {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Column "+name+" not found in the CSV header")
+		}
+		columns[field] = column
+	}
+
+	return columns, nil
+}
+
+// ParseImportRow validates a row of an import as ParseStoreRequest does a
+// request, once its dates are read in the date format of the import.
+func (p *SubscriptionParser) ParseImportRow(ctx *fiber.Ctx, req *dto.ImportSubscriptionsHandlerRequest, row dto.StoreSubscriptionHandlerRequest) (*entity.Subscription, error) {
+	if req.DateLayout != "" {
+		dates := []struct {
+			field string
+			value *string
+		}{
+			{"start_date", &row.StartDate},
+			{"end_date", &row.EndDate},
+			{"trial_start_date", &row.TrialStartDate},
+			{"trial_end_date", &row.TrialEndDate},
+		}
+		for _, date := range dates {
+			if *date.value == "" {
+				continue
+			}
+			parsed, err := time.Parse(req.DateLayout, *date.value)
+			if err != nil {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid "+date.field+", must match "+req.DateFormat)
+			}
+			*date.value = parsed.Format(time.RFC3339)
+		}
+	}
+
+	return p.parseSubscription(ctx, row)
+}
+
 func (p *SubscriptionParser) ParseDeleteRequest(ctx *fiber.Ctx) (int, error) {
 	return p.ParseGetRequest(ctx)
 }
 
-func (p *SubscriptionParser) ParseTotalCostRequest(ctx *fiber.Ctx) (*dto.TotalCostHandlerRequest, error) {
-    var req dto.TotalCostHandlerRequest
-    if err := ctx.QueryParser(&req); err != nil {
-        return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
-    }
-
-    if req.StartDate == nil || *req.StartDate == "" {
-        return nil, fiber.NewError(fiber.StatusBadRequest, "Start date is required")
-    }
-    if req.EndDate == nil || *req.EndDate == "" {
-        return nil, fiber.NewError(fiber.StatusBadRequest, "End date is required")
-    }
-
-    if _, err := time.Parse("2006-01-02", *req.StartDate); err != nil {
-        return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid start date format. Use YYYY-MM-DD")
-    }
-    if _, err := time.Parse("2006-01-02", *req.EndDate); err != nil {
-        return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid end date format. Use YYYY-MM-DD")
-    }
-
-    if req.UserID != nil && *req.UserID != "" {
-        if _, err := uuid.Parse(*req.UserID); err != nil {
-            return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format in filter, must be UUID")
-        }
-    }
-
-    return &req, nil
-}
\ No newline at end of file
+func (p *SubscriptionParser) ParseStorePriceChangeRequest(ctx *fiber.Ctx) (*entity.PriceChange, error) {
+	id, err := p.ParseGetRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req dto.StorePriceChangeHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if req.Price == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Price is required")
+	}
+	if req.EffectiveDate == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Effective date is required")
+	}
+
+	currency, err := p.subscriptionCurrency(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := parseMoney(req.Price, currency, "price")
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveDate, err := time.Parse(time.RFC3339, req.EffectiveDate)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid effective date format. Use RFC3339 format")
+	}
+
+	return &entity.PriceChange{
+		SubscriptionID: int64(id),
+		Price:          price,
+		Currency:       currency,
+		EffectiveDate:  effectiveDate,
+	}, nil
+}
+
+func (p *SubscriptionParser) ParseStoreDiscountRequest(ctx *fiber.Ctx) (*entity.Discount, error) {
+	id, err := p.ParseGetRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := p.subscriptionCurrency(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := parseDiscount(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	discount.SubscriptionID = int64(id)
+
+	return discount, nil
+}
+
+func (p *SubscriptionParser) ParseUpdateDiscountRequest(ctx *fiber.Ctx) (*entity.Discount, error) {
+	id, discountID, err := p.ParseDiscountIDRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := p.subscriptionCurrency(ctx, int(id))
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := parseDiscount(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	discount.Id = discountID
+	discount.SubscriptionID = id
+
+	return discount, nil
+}
+
+// ParseDiscountIDRequest parses the subscription and discount IDs from the path.
+func (p *SubscriptionParser) ParseDiscountIDRequest(ctx *fiber.Ctx) (int64, int64, error) {
+	id, err := p.ParseGetRequest(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	discountID, err := strconv.ParseInt(ctx.Params("discount_id"), 10, 64)
+	if err != nil {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid discount ID format")
+	}
+
+	return int64(id), discountID, nil
+}
+
+// parseDiscount parses a discount of a subscription billed in currency, a
+// fixed one is given in its major units.
+func parseDiscount(ctx *fiber.Ctx, currency string) (*entity.Discount, error) {
+	var req dto.DiscountHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	discount := &entity.Discount{Kind: entity.DiscountKind(req.Kind), Currency: currency}
+	if !discount.Kind.Valid() {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid discount kind. Use percent or fixed")
+	}
+
+	if discount.Kind == entity.DiscountFixed {
+		value, err := parseMoney(req.Value, currency, "discount value")
+		if err != nil {
+			return nil, err
+		}
+		if value == 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid discount value format")
+		}
+		discount.Value = uint64(value)
+	} else {
+		value, err := strconv.ParseUint(req.Value, 10, 63)
+		if err != nil || value == 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid discount value format")
+		}
+		if value > 100 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Percent discount must not exceed 100")
+		}
+		discount.Value = value
+	}
+
+	var err error
+
+	if req.StartDate != "" {
+		discount.StartDate, err = time.Parse(time.RFC3339, req.StartDate)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid start date format. Use RFC3339 format")
+		}
+	}
+
+	if req.EndDate != "" {
+		discount.EndDate, err = time.Parse(time.RFC3339, req.EndDate)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid end date format. Use RFC3339 format")
+		}
+		if !discount.StartDate.IsZero() && !discount.EndDate.After(discount.StartDate) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "End date must be after start date")
+		}
+	}
+
+	return discount, nil
+}
+
+func (p *SubscriptionParser) ParseStoreTagRequest(ctx *fiber.Ctx) (*entity.Tag, error) {
+	var req dto.StoreTagHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	name := entity.NormalizeTagName(req.Name)
+	if !entity.ValidTagName(name) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid tag name, must be 1 to 64 characters without commas")
+	}
+
+	return &entity.Tag{Name: name}, nil
+}
+
+func (p *SubscriptionParser) ParseUpdateTagRequest(ctx *fiber.Ctx) (*entity.Tag, error) {
+	id, err := p.ParseTagIDRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var req dto.UpdateTagHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	name := entity.NormalizeTagName(req.Name)
+	if !entity.ValidTagName(name) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid tag name, must be 1 to 64 characters without commas")
+	}
+
+	return &entity.Tag{Id: id, Name: name}, nil
+}
+
+func (p *SubscriptionParser) ParseTagIDRequest(ctx *fiber.Ctx) (int64, error) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid tag ID format")
+	}
+
+	return id, nil
+}
+
+func (p *SubscriptionParser) ParseStoreServiceRequest(ctx *fiber.Ctx) (*entity.Service, error) {
+	return parseService(ctx)
+}
+
+func (p *SubscriptionParser) ParseUpdateServiceRequest(ctx *fiber.Ctx) (*entity.Service, error) {
+	id, err := p.ParseServiceIDRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := parseService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	service.Id = id
+
+	return service, nil
+}
+
+func (p *SubscriptionParser) ParseServiceIDRequest(ctx *fiber.Ctx) (int64, error) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid service ID format")
+	}
+
+	return id, nil
+}
+
+// ParseMergeServicesRequest parses the service to keep and the duplicates
+// to fold into it.
+func (p *SubscriptionParser) ParseMergeServicesRequest(ctx *fiber.Ctx) (int64, []int64, error) {
+	id, err := p.ParseServiceIDRequest(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req dto.MergeServicesHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if len(req.DuplicateIDs) == 0 {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Duplicate IDs are required")
+	}
+
+	duplicateIDs := make([]int64, 0, len(req.DuplicateIDs))
+	seen := make(map[int64]bool, len(req.DuplicateIDs))
+	for _, raw := range req.DuplicateIDs {
+		duplicateID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid duplicate service ID format")
+		}
+		if duplicateID == id {
+			return 0, nil, fiber.NewError(fiber.StatusBadRequest, "A service cannot be merged into itself")
+		}
+		if !seen[duplicateID] {
+			seen[duplicateID] = true
+			duplicateIDs = append(duplicateIDs, duplicateID)
+		}
+	}
+
+	return id, duplicateIDs, nil
+}
+
+func parseService(ctx *fiber.Ctx) (*entity.Service, error) {
+	var req dto.ServiceHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	name := entity.NormalizeServiceName(req.Name)
+	if !entity.ValidServiceName(name) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid service name, must be 1 to 255 characters")
+	}
+
+	aliases := entity.ServiceAliases(name, req.Aliases)
+	for _, alias := range aliases {
+		if !entity.ValidServiceName(alias) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid service alias, must be 1 to 255 characters")
+		}
+	}
+
+	return &entity.Service{Name: name, Aliases: aliases}, nil
+}
+
+// findService looks up the catalog entry for the service name of a
+// subscription. A service not seen before comes back without an ID and is
+// added to the catalog when the subscription is stored.
+func (p *SubscriptionParser) findService(ctx *fiber.Ctx, name string) (*entity.Service, error) {
+	service, err := p.catalog.FindService(ctx.Context(), name)
+	if errors.Is(err, entity.ErrServiceNotFound) {
+		return &entity.Service{Name: entity.NormalizeServiceName(name)}, nil
+	}
+	if err != nil {
+		p.logger.Error("failed to find service", "service_name", name, "error", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to find service")
+	}
+
+	return service, nil
+}
+
+// resolvePlan looks up the plan a subscription is put on, which has to be
+// one of the plans of its service.
+func (p *SubscriptionParser) resolvePlan(ctx *fiber.Ctx, service *entity.Service, rawID string) (*entity.Plan, error) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid plan ID format")
+	}
+
+	// a service that is not in the catalog yet has no plans
+	plan := &entity.Plan{}
+	if service.Id == 0 {
+		err = entity.ErrPlanNotFound
+	} else {
+		plan, err = p.catalog.GetPlan(ctx.Context(), service.Id, id)
+	}
+	if errors.Is(err, entity.ErrPlanNotFound) {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Plan "+rawID+" not found for service "+service.Name)
+	}
+	if err != nil {
+		p.logger.Error("failed to get plan", "plan_id", id, "error", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get plan")
+	}
+
+	return plan, nil
+}
+
+func (p *SubscriptionParser) ParseStorePlanRequest(ctx *fiber.Ctx) (*entity.Plan, error) {
+	serviceID, err := p.ParseServiceIDRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := parsePlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan.ServiceID = serviceID
+
+	return plan, nil
+}
+
+func (p *SubscriptionParser) ParseUpdatePlanRequest(ctx *fiber.Ctx) (*entity.Plan, error) {
+	serviceID, id, err := p.ParsePlanIDRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := parsePlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan.Id = id
+	plan.ServiceID = serviceID
+
+	return plan, nil
+}
+
+// ParsePlanIDRequest parses the service and plan IDs from the path.
+func (p *SubscriptionParser) ParsePlanIDRequest(ctx *fiber.Ctx) (int64, int64, error) {
+	serviceID, err := p.ParseServiceIDRequest(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	id, err := strconv.ParseInt(ctx.Params("plan_id"), 10, 64)
+	if err != nil {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid plan ID format")
+	}
+
+	return serviceID, id, nil
+}
+
+func (p *SubscriptionParser) ParsePlanUsageRequest(ctx *fiber.Ctx) (*int64, time.Time, error) {
+	var req dto.PlanUsageHandlerRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return nil, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	var serviceID *int64
+	if req.ServiceID != nil && *req.ServiceID != "" {
+		id, err := strconv.ParseInt(*req.ServiceID, 10, 64)
+		if err != nil {
+			return nil, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid service ID format")
+		}
+		serviceID = &id
+	}
+
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.Date != nil && *req.Date != "" {
+		parsed, err := time.Parse("2006-01-02", *req.Date)
+		if err != nil {
+			return nil, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
+		}
+		date = parsed
+	}
+
+	return serviceID, date, nil
+}
+
+func parsePlan(ctx *fiber.Ctx) (*entity.Plan, error) {
+	var req dto.PlanHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > entity.MaxPlanNameLength {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid plan name, must be 1 to 64 characters")
+	}
+
+	if req.Price == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Price is required")
+	}
+
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := parseMoney(req.Price, currency, "price")
+	if err != nil {
+		return nil, err
+	}
+
+	if req.BillingPeriod == "" {
+		req.BillingPeriod = string(entity.BillingMonthly)
+	}
+	period, periodDays, err := parseBillingPeriod(req.BillingPeriod, req.BillingPeriodDays)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Plan{
+		Name:              name,
+		Price:             price,
+		Currency:          currency,
+		BillingPeriod:     period,
+		BillingPeriodDays: periodDays,
+	}, nil
+}
+
+func (p *SubscriptionParser) ParseReplaceSubscriptionTagsRequest(ctx *fiber.Ctx) (int, []string, error) {
+	id, err := p.ParseGetRequest(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req dto.ReplaceSubscriptionTagsHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	names, err := parseTagNames(req.Tags)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, names, nil
+}
+
+// ParseUserRequest parses the user ID from the path.
+func (p *SubscriptionParser) ParseUserRequest(ctx *fiber.Ctx) (uuid.UUID, error) {
+	userID, err := uuid.Parse(ctx.Params("user_id"))
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format, must be UUID")
+	}
+
+	return userID, nil
+}
+
+func (p *SubscriptionParser) ParseStorePaymentMethodRequest(ctx *fiber.Ctx) (*entity.PaymentMethod, error) {
+	userID, err := p.ParseUserRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := parsePaymentMethod(ctx)
+	if err != nil {
+		return nil, err
+	}
+	method.UserID = userID
+
+	return method, nil
+}
+
+func (p *SubscriptionParser) ParseUpdatePaymentMethodRequest(ctx *fiber.Ctx) (*entity.PaymentMethod, error) {
+	userID, id, err := p.ParsePaymentMethodIDRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := parsePaymentMethod(ctx)
+	if err != nil {
+		return nil, err
+	}
+	method.Id = id
+	method.UserID = userID
+
+	return method, nil
+}
+
+// ParsePaymentMethodIDRequest parses the user and payment method IDs from the path.
+func (p *SubscriptionParser) ParsePaymentMethodIDRequest(ctx *fiber.Ctx) (uuid.UUID, int64, error) {
+	userID, err := p.ParseUserRequest(ctx)
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return uuid.Nil, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid payment method ID format")
+	}
+
+	return userID, id, nil
+}
+
+func parsePaymentMethod(ctx *fiber.Ctx) (*entity.PaymentMethod, error) {
+	var req dto.PaymentMethodHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	methodType := entity.PaymentMethodType(strings.ToLower(strings.TrimSpace(req.Type)))
+	if !methodType.Valid() {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid payment method type. Use card, wallet or bank")
+	}
+
+	label := strings.TrimSpace(req.Label)
+	if label == "" || utf8.RuneCountInString(label) > entity.MaxPaymentMethodLabelLength {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid payment method label, must be 1 to 64 characters")
+	}
+
+	lastFour := strings.TrimSpace(req.LastFour)
+	if lastFour != "" && !entity.ValidLastFour(lastFour) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid last four digits, must be exactly 4 digits")
+	}
+
+	return &entity.PaymentMethod{
+		Type:     methodType,
+		Label:    label,
+		LastFour: lastFour,
+	}, nil
+}
+
+// parsePaymentMethodID parses the optional payment method of a subscription.
+func parsePaymentMethodID(raw string) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid payment method ID format")
+	}
+
+	return id, nil
+}
+
+func (p *SubscriptionParser) ParseStoreBudgetRequest(ctx *fiber.Ctx) (*entity.Budget, error) {
+	userID, err := p.ParseUserRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := parseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budget.UserID = userID
+
+	return budget, nil
+}
+
+func (p *SubscriptionParser) ParseUpdateBudgetRequest(ctx *fiber.Ctx) (*entity.Budget, error) {
+	userID, id, err := p.ParseBudgetIDRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := parseBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budget.Id = id
+	budget.UserID = userID
+
+	return budget, nil
+}
+
+// ParseBudgetIDRequest parses the user and budget IDs from the path.
+func (p *SubscriptionParser) ParseBudgetIDRequest(ctx *fiber.Ctx) (uuid.UUID, int64, error) {
+	userID, err := p.ParseUserRequest(ctx)
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return uuid.Nil, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid budget ID format")
+	}
+
+	return userID, id, nil
+}
+
+func (p *SubscriptionParser) ParseBudgetReportRequest(ctx *fiber.Ctx) (uuid.UUID, *dto.BudgetReportHandlerRequest, error) {
+	userID, err := p.ParseUserRequest(ctx)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	var req dto.BudgetReportHandlerRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if req.StartMonth == nil || *req.StartMonth == "" {
+		startMonth := time.Now().Format("2006-01")
+		req.StartMonth = &startMonth
+	}
+	if req.EndMonth == nil || *req.EndMonth == "" {
+		req.EndMonth = req.StartMonth
+	}
+
+	start, err := time.Parse("2006-01", *req.StartMonth)
+	if err != nil {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid start month format. Use YYYY-MM")
+	}
+	end, err := time.Parse("2006-01", *req.EndMonth)
+	if err != nil {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid end month format. Use YYYY-MM")
+	}
+	if end.Before(start) {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusBadRequest, "End month must not be before start month")
+	}
+
+	basis, err := parseCostBasis(req.Basis)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	req.Basis = &basis
+
+	return userID, &req, nil
+}
+
+func parseBudget(ctx *fiber.Ctx) (*entity.Budget, error) {
+	var req dto.BudgetHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if req.MonthlyLimit == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Monthly limit is required")
+	}
+
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := parseMoney(req.MonthlyLimit, currency, "monthly limit")
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid monthly limit format")
+	}
+
+	budget := &entity.Budget{
+		MonthlyLimit: limit,
+		Currency:     currency,
+		ServiceName:  strings.TrimSpace(req.ServiceName),
+	}
+
+	if req.Tag != "" {
+		budget.Tag = entity.NormalizeTagName(req.Tag)
+		if !entity.ValidTagName(budget.Tag) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid tag name, must be 1 to 64 characters without commas")
+		}
+	}
+
+	if budget.Tag != "" && budget.ServiceName != "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Budget can be limited to either a tag or a service, not both")
+	}
+
+	return budget, nil
+}
+
+// ParseReplaceSharesRequest parses the members a subscription is split
+// across, each paying either a weighted part or a fixed amount.
+func (p *SubscriptionParser) ParseReplaceSharesRequest(ctx *fiber.Ctx) (int, []entity.Share, error) {
+	id, err := p.ParseGetRequest(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req dto.ReplaceSharesHandlerRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	currency, err := p.subscriptionCurrency(ctx, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	shares := make([]entity.Share, len(req.Shares))
+	seen := make(map[uuid.UUID]bool, len(req.Shares))
+	for i, item := range req.Shares {
+		userID, err := uuid.Parse(item.UserID)
+		if err != nil {
+			return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format")
+		}
+		if seen[userID] {
+			return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Each user can hold only one share")
+		}
+		seen[userID] = true
+
+		share := entity.Share{SubscriptionID: int64(id), UserID: userID, Currency: currency}
+		switch {
+		case item.Weight != "" && item.FixedAmount != "":
+			return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Share must have either a weight or a fixed amount, not both")
+		case item.Weight != "":
+			weight, err := strconv.ParseUint(item.Weight, 10, 32)
+			if err != nil || weight == 0 {
+				return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid weight format")
+			}
+			share.Weight = weight
+		case item.FixedAmount != "":
+			amount, err := parseMoney(item.FixedAmount, currency, "fixed amount")
+			if err != nil {
+				return 0, nil, err
+			}
+			share.FixedAmount = &amount
+		default:
+			return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Share must have a weight or a fixed amount")
+		}
+
+		shares[i] = share
+	}
+
+	return id, shares, nil
+}
+
+func (p *SubscriptionParser) ParseTotalCostRequest(ctx *fiber.Ctx) (*dto.TotalCostHandlerRequest, error) {
+	req, err := parseCostQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return (*dto.TotalCostHandlerRequest)(req), nil
+}
+
+func (p *SubscriptionParser) ParseCostBreakdownRequest(ctx *fiber.Ctx) (*dto.CostBreakdownHandlerRequest, error) {
+	req, err := parseCostQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return (*dto.CostBreakdownHandlerRequest)(req), nil
+}
+
+// parseCostQuery parses the query parameters the total cost and the cost
+// breakdown have in common.
+func parseCostQuery(ctx *fiber.Ctx) (*dto.CostQueryHandlerRequest, error) {
+	var req dto.CostQueryHandlerRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := validateCostQuery(req.UserID, req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	basis, err := parseCostBasis(req.Basis)
+	if err != nil {
+		return nil, err
+	}
+	req.Basis = &basis
+
+	var currency string
+	if req.Currency != nil {
+		currency = *req.Currency
+	}
+	currency, err = parseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	req.Currency = &currency
+
+	tags, err := parseTagFilter(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	req.TagNames = tags
+
+	groupBy, err := parseCostGroupBy(req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	req.GroupBy = &groupBy
+
+	return &req, nil
+}
+
+func (p *SubscriptionParser) ParseForecastRequest(ctx *fiber.Ctx) (*dto.ForecastHandlerRequest, error) {
+	var req dto.ForecastHandlerRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if req.Months == nil {
+		months := 12
+		req.Months = &months
+	}
+	if *req.Months < 1 || *req.Months > 60 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Months must be between 1 and 60")
+	}
+
+	if req.UserID != nil && *req.UserID != "" {
+		if _, err := uuid.Parse(*req.UserID); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format in filter, must be UUID")
+		}
+	}
+
+	var currency string
+	if req.Currency != nil {
+		currency = *req.Currency
+	}
+	currency, err := parseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	req.Currency = &currency
+
+	return &req, nil
+}
+
+// validateCostQuery checks the query parameters shared by the cost endpoints.
+func validateCostQuery(userID, startDate, endDate *string) error {
+	if startDate == nil || *startDate == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Start date is required")
+	}
+	if endDate == nil || *endDate == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "End date is required")
+	}
+
+	start, err := time.Parse("2006-01-02", *startDate)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid start date format. Use YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", *endDate)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid end date format. Use YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return fiber.NewError(fiber.StatusBadRequest, "End date must not be before start date")
+	}
+
+	if userID != nil && *userID != "" {
+		if _, err := uuid.Parse(*userID); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format in filter, must be UUID")
+		}
+	}
+
+	return nil
+}
+
+func parseCostBasis(basis *string) (string, error) {
+	if basis == nil || *basis == "" {
+		return string(entity.CostBasisCash), nil
+	}
+	if !entity.CostBasis(*basis).Valid() {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid basis. Use cash or accrual")
+	}
+	return *basis, nil
+}
+
+func parseCostGroupBy(groupBy *string) (string, error) {
+	if groupBy == nil {
+		return string(entity.CostGroupByNone), nil
+	}
+	if !entity.CostGroupBy(*groupBy).Valid() {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid group_by. Use service_name, user_id, tag or payment_method")
+	}
+	return *groupBy, nil
+}
+
+// parseTagFilter splits a comma separated list of tag names.
+func parseTagFilter(tags *string) ([]string, error) {
+	if tags == nil || *tags == "" {
+		return nil, nil
+	}
+
+	return parseTagNames(strings.Split(*tags, ","))
+}
+
+// parseTagNames normalizes tag names, dropping duplicates.
+func parseTagNames(names []string) ([]string, error) {
+	parsed := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = entity.NormalizeTagName(name)
+		if !entity.ValidTagName(name) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid tag name, must be 1 to 64 characters without commas")
+		}
+		if !seen[name] {
+			seen[name] = true
+			parsed = append(parsed, name)
+		}
+	}
+
+	return parsed, nil
+}
+
+// parseTrial parses an optional free trial, which starts with the subscription
+// unless told otherwise.
+func parseTrial(startDate time.Time, trialStartDate, trialEndDate string) (time.Time, time.Time, error) {
+	if trialEndDate == "" {
+		if trialStartDate != "" {
+			return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Trial end date is required with trial start date")
+		}
+		return time.Time{}, time.Time{}, nil
+	}
+
+	trialEnd, err := time.Parse(time.RFC3339, trialEndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid trial end date format. Use RFC3339 format")
+	}
+
+	trialStart := startDate
+	if trialStartDate != "" {
+		trialStart, err = time.Parse(time.RFC3339, trialStartDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid trial start date format. Use RFC3339 format")
+		}
+	}
+
+	if trialStart.Before(startDate) {
+		return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Trial must not start before the subscription")
+	}
+	if !trialEnd.After(trialStart) {
+		return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Trial end date must be after trial start date")
+	}
+
+	return trialStart, trialEnd, nil
+}
+
+func parseBillingPeriod(period, days string) (entity.BillingPeriod, int, error) {
+	billingPeriod := entity.BillingPeriod(period)
+	if !billingPeriod.Valid() {
+		return "", 0, fiber.NewError(fiber.StatusBadRequest, "Invalid billing period. Use weekly, monthly, quarterly, yearly or custom")
+	}
+
+	if billingPeriod != entity.BillingCustom {
+		if days != "" {
+			return "", 0, fiber.NewError(fiber.StatusBadRequest, "Billing period days are only allowed for custom billing period")
+		}
+		return billingPeriod, 0, nil
+	}
+
+	if days == "" {
+		return "", 0, fiber.NewError(fiber.StatusBadRequest, "Billing period days are required for custom billing period")
+	}
+	periodDays, err := strconv.Atoi(days)
+	if err != nil || periodDays < 1 {
+		return "", 0, fiber.NewError(fiber.StatusBadRequest, "Invalid billing period days, must be a positive integer")
+	}
+
+	return billingPeriod, periodDays, nil
+}
+
+// parseTaxProfile parses an optional tax region along with whether the price
+// includes the tax, inclusive being the default for the latter.
+func parseTaxProfile(region, inclusive string, defaultInclusive bool) (string, bool, error) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if region != "" && !entity.ValidTaxRegion(region) {
+		return "", false, fiber.NewError(fiber.StatusBadRequest, "Invalid tax region, must be an ISO 3166 country or subdivision code")
+	}
+
+	if inclusive == "" {
+		return region, defaultInclusive, nil
+	}
+
+	taxInclusive, err := strconv.ParseBool(inclusive)
+	if err != nil {
+		return "", false, fiber.NewError(fiber.StatusBadRequest, "Invalid tax inclusive flag, must be true or false")
+	}
+
+	return region, taxInclusive, nil
+}
+
+// parseNotes trims free-form subscription notes.
+func parseNotes(notes string) (string, error) {
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > entity.MaxNotesLength {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Notes must not exceed 4000 characters")
+	}
+	return notes, nil
+}
+
+// parseAttributes validates the custom attributes of a subscription.
+func parseAttributes(attributes map[string]string) (map[string]string, error) {
+	if len(attributes) > entity.MaxAttributes {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "A subscription can hold at most 32 attributes")
+	}
+
+	parsed := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		if !entity.ValidAttributeKey(key) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid attribute key "+key+", must be 1 to 64 letters, digits, _ or -")
+		}
+		if !entity.ValidAttributeValue(value) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Attribute "+key+" must not exceed 256 characters")
+		}
+		parsed[key] = value
+	}
+
+	return parsed, nil
+}
+
+// attributePrefix marks the query parameters that filter a list by a
+// custom attribute, attr.owner_team=billing, and the import fields holding one.
+const attributePrefix = "attr."
+
+// parseAttributeFilters collects the attr.<key>=<value> query filters.
+func parseAttributeFilters(ctx *fiber.Ctx) (map[string]string, error) {
+	var filters map[string]string
+	for param, value := range ctx.Queries() {
+		key, ok := strings.CutPrefix(param, attributePrefix)
+		if !ok {
+			continue
+		}
+		if !entity.ValidAttributeKey(key) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid attribute filter "+param)
+		}
+		if filters == nil {
+			filters = map[string]string{}
+		}
+		filters[key] = value
+	}
+
+	return filters, nil
+}
+
+// parseRenewal parses whether a subscription renews and how many days of
+// notice cancelling it takes, either falls back to its default when empty.
+func parseRenewal(autoRenew, noticeDays string, defaultAutoRenew bool, defaultNoticeDays int) (bool, int, error) {
+	renew, days := defaultAutoRenew, defaultNoticeDays
+
+	if autoRenew != "" {
+		parsed, err := strconv.ParseBool(autoRenew)
+		if err != nil {
+			return false, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid auto renew flag, must be true or false")
+		}
+		renew = parsed
+	}
+
+	if noticeDays != "" {
+		parsed, err := strconv.Atoi(noticeDays)
+		if err != nil || parsed < 0 {
+			return false, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid cancellation notice days, must be a non-negative number")
+		}
+		days = parsed
+	}
+
+	return renew, days, nil
+}
+
+// parseMoney parses an amount in the major units of currency, field names
+// it in the error.
+func parseMoney(amount, currency, field string) (entity.Money, error) {
+	money, err := entity.ParseMoney(strings.TrimSpace(amount), currency)
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf(
+			"Invalid %s format, use a decimal amount with at most %d decimal places",
+			field, entity.CurrencyExponent(currency),
+		))
+	}
+	return money, nil
+}
+
+// subscriptionCurrency looks up the currency of the subscription the amounts
+// of a request are given for.
+func (p *SubscriptionParser) subscriptionCurrency(ctx *fiber.Ctx, id int) (string, error) {
+	sub, err := p.catalog.Get(ctx.Context(), id)
+	if errors.Is(err, entity.ErrSubscriptionNotFound) {
+		return "", fiber.NewError(fiber.StatusNotFound, "Subscription not found")
+	}
+	if err != nil {
+		p.logger.Error("failed to get subscription", "subscription_id", id, "error", err)
+		return "", fiber.NewError(fiber.StatusInternalServerError, "Failed to get subscription")
+	}
+
+	return sub.Currency, nil
+}
+
+// parseCurrency normalizes an ISO 4217 code, defaulting to entity.DefaultCurrency.
+func parseCurrency(code string) (string, error) {
+	if code == "" {
+		return entity.DefaultCurrency, nil
+	}
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !entity.ValidCurrency(code) {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid currency, must be an ISO 4217 code")
+	}
+
+	return code, nil
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/controller/http/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMergePatch(t *testing.T) {
+	// the examples of RFC 7396, appendix A, among others
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{target: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{target: `{"a":"foo"}`, patch: `{"a":{"b":"c"}}`, want: `{"a":{"b":"c"}}`},
+		{target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+		{target: `{"notes":"x","attributes":{"k":"v","l":"w"}}`, patch: `{"attributes":{"k":null}}`, want: `{"notes":"x","attributes":{"l":"w"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" "+tt.patch, func(t *testing.T) {
+			target, patch, want := jsonObject(t, tt.target), jsonObject(t, tt.patch), jsonObject(t, tt.want)
+			if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergePatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func jsonObject(t *testing.T, raw string) map[string]any {
+	t.Helper()
+
+	var object map[string]any
+	if err := json.Unmarshal([]byte(raw), &object); err != nil {
+		t.Fatalf("unmarshal %s: %v", raw, err)
+	}
+	return object
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		want     []int64
+		wantCode int
+	}{
+		{header: ""},
+		{header: "*"},
+		{header: ` * `},
+		{header: `"3"`, want: []int64{3}},
+		{header: `"3", "5"`, want: []int64{3, 5}},
+		{header: `"3",junk`, want: []int64{3}},
+		{header: `W/"3"`, wantCode: fiber.StatusPreconditionFailed},
+		{header: `3`, wantCode: fiber.StatusPreconditionFailed},
+		{header: `"abc"`, wantCode: fiber.StatusPreconditionFailed},
+		{header: `"0"`, wantCode: fiber.StatusPreconditionFailed},
+		{header: `"-2"`, wantCode: fiber.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header)
+
+			var fiberErr *fiber.Error
+			switch {
+			case tt.wantCode == 0 && err != nil:
+				t.Fatalf("parseIfMatch() error = %v", err)
+			case tt.wantCode != 0 && (!errors.As(err, &fiberErr) || fiberErr.Code != tt.wantCode):
+				t.Fatalf("parseIfMatch() error = %v, want status %d", err, tt.wantCode)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseIfMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportRequest(t *testing.T) {
+	csv := "service_name,price,user_id,start_date\n" +
+		"Netflix,299,60601fee-2bf1-4721-ae6f-7636e79a0cba,2024-01-01T00:00:00Z\n" +
+		"Spotify,199\n" +
+		"Kinopoisk,99,60601fee-2bf1-4721-ae6f-7636e79a0cba,2024-01-01T00:00:00Z,extra\n" +
+		"\"Yandex Plus,299,60601fee-2bf1-4721-ae6f-7636e79a0cba,2024-01-01T00:00:00Z\n"
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "subscriptions.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte(csv)); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got *dto.ImportSubscriptionsHandlerRequest
+	app := fiber.New()
+	app.Post("/", func(ctx *fiber.Ctx) error {
+		var err error
+		got, err = New(nil, nil).ParseImportRequest(ctx)
+		return err
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/", &body)
+	req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("ParseImportRequest() status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	wantLines := []int{2, 3, 4, 5}
+	if !reflect.DeepEqual(got.Lines, wantLines) {
+		t.Errorf("Lines = %v, want %v", got.Lines, wantLines)
+	}
+	// the short row and the one with an unterminated quote are invalid, the
+	// extra column is ignored
+	for i, invalid := range []bool{false, true, false, true} {
+		if (got.Errors[i] != "") != invalid {
+			t.Errorf("Errors[%d] = %q, want invalid %v", i, got.Errors[i], invalid)
+		}
+	}
+	if got.Rows[2].ServiceName != "Kinopoisk" || got.Rows[2].Price != "99" {
+		t.Errorf("Rows[2] = %+v, want Kinopoisk at 99", got.Rows[2])
+	}
+}
@@ -42,7 +42,7 @@ func NewRouter(app *fiber.App, cfg *config.Config, u usecase.SubscriptionUsecase
 	app.Get("/readyz", func(ctx *fiber.Ctx) error { return ctx.SendStatus(http.StatusOK) })
 
 	// parser and mapper
-	subscriptionParser := parser.New(l)
+	subscriptionParser := parser.New(l, u)
 	subscriptionMapper := mapper.New()
 	
 	subscriptionHandler := handler.New(u, l, subscriptionParser, subscriptionMapper)
@@ -50,14 +50,75 @@ func NewRouter(app *fiber.App, cfg *config.Config, u usecase.SubscriptionUsecase
 	// API routes
 	api := app.Group("/v1")
 	{
+		// the colon of a custom method is escaped, it would start a parameter
+		api.Post("/subscriptions\\:batch", middleware.Idempotency(u, l, cfg.Idempotency.KeyTTL), subscriptionHandler.Batch)
+
 		subscriptions := api.Group("/subscriptions")
 		{
-			subscriptions.Post("/", subscriptionHandler.Store)
+			subscriptions.Post("/", middleware.Idempotency(u, l, cfg.Idempotency.KeyTTL), subscriptionHandler.Store)
 			subscriptions.Get("/", subscriptionHandler.List)
 			subscriptions.Get("/total-cost", subscriptionHandler.GetTotalCost)
+			subscriptions.Get("/cost-breakdown", subscriptionHandler.GetCostBreakdown)
+			subscriptions.Get("/forecast", subscriptionHandler.Forecast)
+			subscriptions.Post("/import", subscriptionHandler.Import)
 			subscriptions.Get("/:id", subscriptionHandler.Get)
 			subscriptions.Put("/:id", subscriptionHandler.Update)
+			subscriptions.Patch("/:id", subscriptionHandler.Patch)
 			subscriptions.Delete("/:id", subscriptionHandler.Delete)
+			subscriptions.Post("/:id/price-changes", subscriptionHandler.StorePriceChange)
+			subscriptions.Get("/:id/price-changes", subscriptionHandler.ListPriceChanges)
+			subscriptions.Post("/:id/pause", subscriptionHandler.Pause)
+			subscriptions.Post("/:id/resume", subscriptionHandler.Resume)
+			subscriptions.Post("/:id/cancel", subscriptionHandler.Cancel)
+			subscriptions.Put("/:id/shares", subscriptionHandler.ReplaceShares)
+			subscriptions.Get("/:id/shares", subscriptionHandler.ListShares)
+			subscriptions.Post("/:id/discounts", subscriptionHandler.StoreDiscount)
+			subscriptions.Get("/:id/discounts", subscriptionHandler.ListDiscounts)
+			subscriptions.Get("/:id/discounts/:discount_id", subscriptionHandler.GetDiscount)
+			subscriptions.Put("/:id/discounts/:discount_id", subscriptionHandler.UpdateDiscount)
+			subscriptions.Delete("/:id/discounts/:discount_id", subscriptionHandler.DeleteDiscount)
+			subscriptions.Put("/:id/tags", subscriptionHandler.ReplaceSubscriptionTags)
+		}
+
+		tags := api.Group("/tags")
+		{
+			tags.Post("/", subscriptionHandler.StoreTag)
+			tags.Get("/", subscriptionHandler.ListTags)
+			tags.Get("/:id", subscriptionHandler.GetTag)
+			tags.Put("/:id", subscriptionHandler.UpdateTag)
+			tags.Delete("/:id", subscriptionHandler.DeleteTag)
+		}
+
+		services := api.Group("/services")
+		{
+			services.Post("/", subscriptionHandler.StoreService)
+			services.Get("/", subscriptionHandler.ListServices)
+			services.Get("/plan-usage", subscriptionHandler.PlanUsage)
+			services.Get("/:id", subscriptionHandler.GetService)
+			services.Put("/:id", subscriptionHandler.UpdateService)
+			services.Delete("/:id", subscriptionHandler.DeleteService)
+			services.Post("/:id/merge", subscriptionHandler.MergeServices)
+			services.Post("/:id/plans", subscriptionHandler.StorePlan)
+			services.Get("/:id/plans", subscriptionHandler.ListPlans)
+			services.Get("/:id/plans/:plan_id", subscriptionHandler.GetPlan)
+			services.Put("/:id/plans/:plan_id", subscriptionHandler.UpdatePlan)
+			services.Delete("/:id/plans/:plan_id", subscriptionHandler.DeletePlan)
+		}
+
+		users := api.Group("/users")
+		{
+			users.Post("/:user_id/payment-methods", subscriptionHandler.StorePaymentMethod)
+			users.Get("/:user_id/payment-methods", subscriptionHandler.ListPaymentMethods)
+			users.Get("/:user_id/payment-methods/:id", subscriptionHandler.GetPaymentMethod)
+			users.Put("/:user_id/payment-methods/:id", subscriptionHandler.UpdatePaymentMethod)
+			users.Delete("/:user_id/payment-methods/:id", subscriptionHandler.DeletePaymentMethod)
+			users.Get("/:user_id/payment-methods/:id/subscriptions", subscriptionHandler.ListPaymentMethodSubscriptions)
+			users.Post("/:user_id/budgets", subscriptionHandler.StoreBudget)
+			users.Get("/:user_id/budgets", subscriptionHandler.ListBudgets)
+			users.Get("/:user_id/budgets/report", subscriptionHandler.BudgetReport)
+			users.Get("/:user_id/budgets/:id", subscriptionHandler.GetBudget)
+			users.Put("/:user_id/budgets/:id", subscriptionHandler.UpdateBudget)
+			users.Delete("/:user_id/budgets/:id", subscriptionHandler.DeleteBudget)
 		}
 	}
 }
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	// MaxNotesLength is the longest subscription notes in characters.
+	MaxNotesLength = 4000
+	// MaxAttributes is how many custom attributes a subscription can hold.
+	MaxAttributes = 32
+	// MaxAttributeValueLength is the longest attribute value in characters.
+	MaxAttributeValueLength = 256
+)
+
+// attributeKeyPattern keeps attribute keys usable as attr.<key> query filters.
+var attributeKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidAttributeKey reports whether key can name a custom attribute, such as
+// account_email or owner-team.
+func ValidAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
+
+// ValidAttributeValue reports whether value fits a custom attribute.
+func ValidAttributeValue(value string) bool {
+	return utf8.RuneCountInString(value) <= MaxAttributeValueLength
+}
@@ -0,0 +1,193 @@
+package entity
+
+import "time"
+
+// BillingPeriod is the interval a subscription price is charged for.
+type BillingPeriod string
+
+const (
+	BillingWeekly    BillingPeriod = "weekly"
+	BillingMonthly   BillingPeriod = "monthly"
+	BillingQuarterly BillingPeriod = "quarterly"
+	BillingYearly    BillingPeriod = "yearly"
+	// BillingCustom charges every BillingPeriodDays days.
+	BillingCustom BillingPeriod = "custom"
+)
+
+func (p BillingPeriod) Valid() bool {
+	switch p {
+	case BillingWeekly, BillingMonthly, BillingQuarterly, BillingYearly, BillingCustom:
+		return true
+	}
+	return false
+}
+
+// BillingAnchor is the date of the first charge after the free trial: the end
+// of the trial if the subscription has one, the start date otherwise.
+func (s *Subscription) BillingAnchor() time.Time {
+	if s.TrialEndDate.After(s.StartDate) {
+		return s.TrialEndDate
+	}
+	return s.StartDate
+}
+
+// ChargeDate returns the date of the n-th charge, the first being the zeroth.
+func (s *Subscription) ChargeDate(n int) time.Time {
+	return s.chargeSchedule()(n).Start
+}
+
+// chargeSchedule returns the span paid for by the n-th charge. A trial that
+// starts after the subscription leaves the charges from the start date until
+// then in place, the last of them cut short where the trial begins, and the
+// charges after it are anchored at its end.
+func (s *Subscription) chargeSchedule() func(n int) ChargePeriod {
+	anchor := s.BillingAnchor()
+	period := func(anchor time.Time, n int) ChargePeriod {
+		return ChargePeriod{Start: s.addPeriods(anchor, n), End: s.addPeriods(anchor, n+1)}
+	}
+
+	lateTrial := s.TrialEndDate.After(s.StartDate) && s.TrialStartDate.After(s.StartDate)
+	if !lateTrial || s.BillingPeriod == BillingCustom && s.BillingPeriodDays <= 0 {
+		return func(n int) ChargePeriod { return period(anchor, n) }
+	}
+
+	beforeTrial := 0
+	for s.addPeriods(s.StartDate, beforeTrial).Before(s.TrialStartDate) {
+		beforeTrial++
+	}
+
+	return func(n int) ChargePeriod {
+		if n >= beforeTrial {
+			return period(anchor, n-beforeTrial)
+		}
+		p := period(s.StartDate, n)
+		if p.End.After(s.TrialStartDate) {
+			p.End = s.TrialStartDate
+		}
+		return p
+	}
+}
+
+// addPeriods adds n billing periods to anchor.
+func (s *Subscription) addPeriods(anchor time.Time, n int) time.Time {
+	switch s.BillingPeriod {
+	case BillingWeekly:
+		return anchor.AddDate(0, 0, 7*n)
+	case BillingQuarterly:
+		return addMonths(anchor, 3*n)
+	case BillingYearly:
+		return addMonths(anchor, 12*n)
+	case BillingCustom:
+		return anchor.AddDate(0, 0, s.BillingPeriodDays*n)
+	default:
+		return addMonths(anchor, n)
+	}
+}
+
+// ChargePeriod is the span of service paid for by a single charge, End is exclusive.
+type ChargePeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ChargePeriods returns the full billing periods that overlap [from, to)
+// and start while the subscription is still active.
+func (s *Subscription) ChargePeriods(from, to time.Time) []ChargePeriod {
+	if s.BillingPeriod == BillingCustom && s.BillingPeriodDays <= 0 {
+		return nil
+	}
+
+	var periods []ChargePeriod
+	schedule := s.chargeSchedule()
+	for n := 0; ; n++ {
+		period := schedule(n)
+		if !period.Start.Before(to) || (!s.EndDate.IsZero() && !period.Start.Before(s.EndDate)) {
+			break
+		}
+		if period.End.After(from) {
+			periods = append(periods, period)
+		}
+	}
+
+	return periods
+}
+
+// Charges returns the billing dates within [from, to) on which the
+// subscription is still active.
+func (s *Subscription) Charges(from, to time.Time) []time.Time {
+	var charges []time.Time
+	for _, period := range s.ChargePeriods(from, to) {
+		if !period.Start.Before(from) {
+			charges = append(charges, period.Start)
+		}
+	}
+
+	return charges
+}
+
+// addMonths adds n months to t, clamping the day to the end of the target
+// month so that a subscription started on the 31st is charged on the 30th
+// in April rather than on the 1st of May.
+func addMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// NextBillingDate returns the first charge on or after t, skipping the
+// charges that fall within a pause. It reports false when no charge is
+// left: the subscription has ended or is paused with no resume date.
+func (s *Subscription) NextBillingDate(t time.Time) (time.Time, bool) {
+	if s.BillingPeriod == BillingCustom && s.BillingPeriodDays <= 0 {
+		return time.Time{}, false
+	}
+
+	schedule := s.chargeSchedule()
+	for n := 0; ; n++ {
+		charge := schedule(n).Start
+		if !s.EndDate.IsZero() && !charge.Before(s.EndDate) {
+			return time.Time{}, false
+		}
+		if charge.Before(t) {
+			continue
+		}
+		if s.pausedIndefinitelyAt(charge) {
+			return time.Time{}, false
+		}
+		if !s.PausedAt(charge) {
+			return charge, true
+		}
+	}
+}
+
+// RemainingCharges counts the charges from t until the end date, skipping
+// the ones that fall within a pause. It reports false for an open-ended
+// subscription, whose charges do not run out.
+func (s *Subscription) RemainingCharges(t time.Time) (int, bool) {
+	if s.EndDate.IsZero() {
+		return 0, false
+	}
+
+	remaining := 0
+	for _, charge := range s.Charges(t, s.EndDate) {
+		if !s.PausedAt(charge) {
+			remaining++
+		}
+	}
+
+	return remaining, true
+}
+
+// pausedIndefinitelyAt reports whether t falls within a pause that has not
+// been resumed yet.
+func (s *Subscription) pausedIndefinitelyAt(t time.Time) bool {
+	for _, pause := range s.Pauses {
+		if pause.EndDate.IsZero() && !t.Before(pause.StartDate) {
+			return true
+		}
+	}
+	return false
+}
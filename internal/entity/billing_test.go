@@ -0,0 +1,309 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func equalDates(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCharges(t *testing.T) {
+	tests := []struct {
+		name     string
+		sub      Subscription
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name: "monthly from the 31st clamps to the end of shorter months",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 31)},
+			from: date(2024, time.January, 1),
+			to:   date(2024, time.June, 1),
+			want: []time.Time{date(2024, time.January, 31), date(2024, time.February, 29), date(2024, time.March, 31), date(2024, time.April, 30), date(2024, time.May, 31)},
+		},
+		{
+			name: "monthly from the 31st in a common year",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2023, time.January, 31)},
+			from: date(2023, time.February, 1),
+			to:   date(2023, time.March, 1),
+			want: []time.Time{date(2023, time.February, 28)},
+		},
+		{
+			name: "yearly from a leap day",
+			sub:  Subscription{BillingPeriod: BillingYearly, StartDate: date(2024, time.February, 29)},
+			from: date(2024, time.January, 1),
+			to:   date(2029, time.January, 1),
+			want: []time.Time{date(2024, time.February, 29), date(2025, time.February, 28), date(2026, time.February, 28), date(2027, time.February, 28), date(2028, time.February, 29)},
+		},
+		{
+			name: "quarterly from the 30th",
+			sub:  Subscription{BillingPeriod: BillingQuarterly, StartDate: date(2023, time.November, 30)},
+			from: date(2023, time.November, 1),
+			to:   date(2024, time.December, 1),
+			want: []time.Time{date(2023, time.November, 30), date(2024, time.February, 29), date(2024, time.May, 30), date(2024, time.August, 30), date(2024, time.November, 30)},
+		},
+		{
+			name: "weekly up to the end date",
+			sub:  Subscription{BillingPeriod: BillingWeekly, StartDate: date(2024, time.January, 1), EndDate: date(2024, time.January, 22)},
+			from: date(2024, time.January, 1),
+			to:   date(2024, time.February, 1),
+			want: []time.Time{date(2024, time.January, 1), date(2024, time.January, 8), date(2024, time.January, 15)},
+		},
+		{
+			name: "custom period anchored at the end of the trial",
+			sub: Subscription{
+				BillingPeriod:     BillingCustom,
+				BillingPeriodDays: 10,
+				StartDate:         date(2024, time.January, 1),
+				TrialEndDate:      date(2024, time.January, 15),
+			},
+			from: date(2024, time.January, 1),
+			to:   date(2024, time.February, 1),
+			want: []time.Time{date(2024, time.January, 15), date(2024, time.January, 25)},
+		},
+		{
+			name: "trial that starts late skips only the trial",
+			sub: Subscription{
+				BillingPeriod:  BillingMonthly,
+				StartDate:      date(2024, time.January, 1),
+				TrialStartDate: date(2024, time.March, 1),
+				TrialEndDate:   date(2024, time.March, 15),
+			},
+			from: date(2024, time.January, 1),
+			to:   date(2024, time.May, 1),
+			want: []time.Time{date(2024, time.January, 1), date(2024, time.February, 1), date(2024, time.March, 15), date(2024, time.April, 15)},
+		},
+		{
+			name: "custom period without days",
+			sub:  Subscription{BillingPeriod: BillingCustom, StartDate: date(2024, time.January, 1)},
+			from: date(2024, time.January, 1),
+			to:   date(2024, time.February, 1),
+		},
+		{
+			name: "charge before the range is left out",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 15)},
+			from: date(2024, time.February, 1),
+			to:   date(2024, time.March, 1),
+			want: []time.Time{date(2024, time.February, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.Charges(tt.from, tt.to); !equalDates(got, tt.want) {
+				t.Errorf("Charges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChargePeriods(t *testing.T) {
+	tests := []struct {
+		name     string
+		sub      Subscription
+		from, to time.Time
+		want     []ChargePeriod
+	}{
+		{
+			name: "period started before the range overlaps it",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 15)},
+			from: date(2024, time.February, 1),
+			to:   date(2024, time.March, 1),
+			want: []ChargePeriod{
+				{Start: date(2024, time.January, 15), End: date(2024, time.February, 15)},
+				{Start: date(2024, time.February, 15), End: date(2024, time.March, 15)},
+			},
+		},
+		{
+			name: "period ending at the start of the range does not overlap it",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 1)},
+			from: date(2024, time.February, 1),
+			to:   date(2024, time.February, 15),
+			want: []ChargePeriod{
+				{Start: date(2024, time.February, 1), End: date(2024, time.March, 1)},
+			},
+		},
+		{
+			name: "period running into a late trial is cut short",
+			sub: Subscription{
+				BillingPeriod:  BillingMonthly,
+				StartDate:      date(2024, time.January, 1),
+				TrialStartDate: date(2024, time.February, 10),
+				TrialEndDate:   date(2024, time.February, 24),
+			},
+			from: date(2024, time.January, 1),
+			to:   date(2024, time.March, 1),
+			want: []ChargePeriod{
+				{Start: date(2024, time.January, 1), End: date(2024, time.February, 1)},
+				{Start: date(2024, time.February, 1), End: date(2024, time.February, 10)},
+				{Start: date(2024, time.February, 24), End: date(2024, time.March, 24)},
+			},
+		},
+		{
+			name: "no period starts on or after the end date",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 31), EndDate: date(2024, time.February, 29)},
+			from: date(2024, time.January, 1),
+			to:   date(2024, time.December, 1),
+			want: []ChargePeriod{
+				{Start: date(2024, time.January, 31), End: date(2024, time.February, 29)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.sub.ChargePeriods(tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ChargePeriods() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("ChargePeriods()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextBillingDate(t *testing.T) {
+	monthEnd := Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 31)}
+
+	tests := []struct {
+		name   string
+		sub    Subscription
+		at     time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "month-end anchor in February of a leap year",
+			sub:    monthEnd,
+			at:     date(2024, time.February, 10),
+			want:   date(2024, time.February, 29),
+			wantOK: true,
+		},
+		{
+			name:   "charge on the day itself",
+			sub:    monthEnd,
+			at:     date(2024, time.March, 31),
+			want:   date(2024, time.March, 31),
+			wantOK: true,
+		},
+		{
+			name:   "before the start",
+			sub:    monthEnd,
+			at:     date(2023, time.December, 1),
+			want:   date(2024, time.January, 31),
+			wantOK: true,
+		},
+		{
+			name: "ended",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 31), EndDate: date(2024, time.March, 1)},
+			at:   date(2024, time.March, 2),
+		},
+		{
+			name: "charges within a pause are skipped",
+			sub: Subscription{
+				BillingPeriod: BillingMonthly,
+				StartDate:     date(2024, time.January, 31),
+				Pauses:        []Pause{{StartDate: date(2024, time.February, 15), EndDate: date(2024, time.April, 15)}},
+			},
+			at:     date(2024, time.February, 10),
+			want:   date(2024, time.April, 30),
+			wantOK: true,
+		},
+		{
+			name: "within a trial that starts late",
+			sub: Subscription{
+				BillingPeriod:  BillingMonthly,
+				StartDate:      date(2024, time.January, 1),
+				TrialStartDate: date(2024, time.March, 1),
+				TrialEndDate:   date(2024, time.March, 15),
+			},
+			at:     date(2024, time.March, 2),
+			want:   date(2024, time.March, 15),
+			wantOK: true,
+		},
+		{
+			name: "paused with no resume date",
+			sub: Subscription{
+				BillingPeriod: BillingMonthly,
+				StartDate:     date(2024, time.January, 31),
+				Pauses:        []Pause{{StartDate: date(2024, time.February, 15)}},
+			},
+			at: date(2024, time.February, 10),
+		},
+		{
+			name: "custom period without days",
+			sub:  Subscription{BillingPeriod: BillingCustom, StartDate: date(2024, time.January, 1)},
+			at:   date(2024, time.January, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.sub.NextBillingDate(tt.at)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("NextBillingDate() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRemainingCharges(t *testing.T) {
+	tests := []struct {
+		name   string
+		sub    Subscription
+		at     time.Time
+		want   int
+		wantOK bool
+	}{
+		{
+			name:   "up to the end date",
+			sub:    Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 31), EndDate: date(2024, time.June, 1)},
+			at:     date(2024, time.February, 1),
+			want:   4,
+			wantOK: true,
+		},
+		{
+			name: "paused charges are not counted",
+			sub: Subscription{
+				BillingPeriod: BillingMonthly,
+				StartDate:     date(2024, time.January, 31),
+				EndDate:       date(2024, time.June, 1),
+				Pauses:        []Pause{{StartDate: date(2024, time.March, 1), EndDate: date(2024, time.April, 1)}},
+			},
+			at:     date(2024, time.February, 1),
+			want:   3,
+			wantOK: true,
+		},
+		{
+			name: "open-ended",
+			sub:  Subscription{BillingPeriod: BillingMonthly, StartDate: date(2024, time.January, 31)},
+			at:   date(2024, time.February, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.sub.RemainingCharges(tt.at)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RemainingCharges() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBudgetNotFound = errors.New("budget not found")
+	ErrBudgetExists   = errors.New("budget with this scope already exists")
+)
+
+// Budget caps what a user means to spend a month, either on every
+// subscription or only on those with a tag or of a service.
+type Budget struct {
+	Id           int64     `db:"id" json:"id"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	MonthlyLimit Money     `db:"monthly_limit" json:"monthly_limit"`
+	Currency     string    `db:"currency" json:"currency"`
+	Tag          string    `db:"tag" json:"tag"`
+	ServiceName  string    `db:"service_name" json:"service_name"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// BudgetMonth compares the spend of a calendar month with the budget limit.
+type BudgetMonth struct {
+	Month time.Time
+	Limit Money
+	Spent Money
+}
+
+func (m BudgetMonth) Overspent() bool {
+	return m.Spent > m.Limit
+}
+
+// BudgetUsage is the spend of every month of a period against a budget.
+type BudgetUsage struct {
+	Budget Budget
+	Months []BudgetMonth
+}
+
+// Overspent reports whether the limit was exceeded in any of the months.
+func (u BudgetUsage) Overspent() bool {
+	for _, month := range u.Months {
+		if month.Overspent() {
+			return true
+		}
+	}
+	return false
+}
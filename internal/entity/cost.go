@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// CostBasis selects how subscription charges are attributed to a period.
+type CostBasis string
+
+const (
+	// CostBasisCash counts every charge on its billing date.
+	CostBasisCash CostBasis = "cash"
+	// CostBasisAccrual spreads every charge day by day over the period it pays for.
+	CostBasisAccrual CostBasis = "accrual"
+)
+
+func (b CostBasis) Valid() bool {
+	return b == CostBasisCash || b == CostBasisAccrual
+}
+
+// CostGroupBy selects the dimension a cost breakdown is split by.
+type CostGroupBy string
+
+const (
+	CostGroupByNone        CostGroupBy = ""
+	CostGroupByServiceName CostGroupBy = "service_name"
+	CostGroupByUserID      CostGroupBy = "user_id"
+	// CostGroupByTag counts a subscription under each of its tags and
+	// untagged subscriptions under none.
+	CostGroupByTag CostGroupBy = "tag"
+	// CostGroupByPaymentMethod groups by payment method ID, subscriptions
+	// without one are counted under CostGroupNoPaymentMethod.
+	CostGroupByPaymentMethod CostGroupBy = "payment_method"
+)
+
+// CostGroupNoPaymentMethod is the group of subscriptions without a payment method.
+const CostGroupNoPaymentMethod = "none"
+
+func (g CostGroupBy) Valid() bool {
+	switch g {
+	case CostGroupByNone, CostGroupByServiceName, CostGroupByUserID, CostGroupByTag, CostGroupByPaymentMethod:
+		return true
+	}
+	return false
+}
+
+// TotalCost is the spend within a period, with discounts taken off.
+type TotalCost struct {
+	Net   Money
+	Tax   Money
+	Gross Money
+	// Undiscounted is the gross amount that would have been paid without the discounts.
+	Undiscounted Money
+	// Groups split the gross amount by the requested dimension.
+	Groups []GroupCost
+}
+
+// MonthlyCost is the spend of a single calendar month.
+type MonthlyCost struct {
+	Month  time.Time
+	Total  Money
+	Groups []GroupCost
+}
+
+// GroupCost is the spend of a single group within a month.
+type GroupCost struct {
+	Key   string
+	Total Money
+}
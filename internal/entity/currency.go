@@ -0,0 +1,22 @@
+package entity
+
+import "errors"
+
+// DefaultCurrency is used for prices stored before currencies were tracked
+// and for totals requested without a target currency.
+const DefaultCurrency = "RUB"
+
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
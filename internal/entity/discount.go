@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+var ErrDiscountNotFound = errors.New("discount not found")
+
+// DiscountKind selects how a discount reduces a charge.
+type DiscountKind string
+
+const (
+	// DiscountPercent takes Value percent off every charge.
+	DiscountPercent DiscountKind = "percent"
+	// DiscountFixed takes Value, in minor units of the subscription currency,
+	// off every charge.
+	DiscountFixed DiscountKind = "fixed"
+)
+
+func (k DiscountKind) Valid() bool {
+	return k == DiscountPercent || k == DiscountFixed
+}
+
+// Discount reduces the charges billed within [StartDate, EndDate). A zero
+// StartDate applies it from the subscription start, a zero EndDate for good.
+type Discount struct {
+	Id             int64        `db:"id" json:"id"`
+	SubscriptionID int64        `db:"subscription_id" json:"subscription_id"`
+	Kind           DiscountKind `db:"kind" json:"kind"`
+	Value          uint64       `db:"value" json:"value"`
+	Currency       string       `db:"currency" json:"currency"` // of the subscription, a fixed value is in
+	StartDate      time.Time    `db:"start_date" json:"start_date"`
+	EndDate        time.Time    `db:"end_date" json:"end_date"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+}
+
+// ActiveAt reports whether the discount applies to a charge billed at t.
+func (d *Discount) ActiveAt(t time.Time) bool {
+	return (d.StartDate.IsZero() || !t.Before(d.StartDate)) && (d.EndDate.IsZero() || t.Before(d.EndDate))
+}
+
+// DiscountedOn returns amount, charged at t in the major units of the
+// subscription currency, less the discounts active at t.
+// Percentages are taken off first, fixed amounts next, a charge never drops
+// below zero.
+func (s *Subscription) DiscountedOn(t time.Time, amount *big.Rat) *big.Rat {
+	discounted := new(big.Rat).Set(amount)
+
+	for _, kind := range []DiscountKind{DiscountPercent, DiscountFixed} {
+		for _, discount := range s.Discounts {
+			if discount.Kind != kind || !discount.ActiveAt(t) {
+				continue
+			}
+
+			if kind == DiscountPercent {
+				off := big.NewRat(int64(discount.Value), 100)
+				discounted.Sub(discounted, off.Mul(off, discounted))
+			} else {
+				discounted.Sub(discounted, Money(discount.Value).Rat(s.Currency))
+			}
+		}
+	}
+
+	if discounted.Sign() < 0 {
+		discounted.SetInt64(0)
+	}
+
+	return discounted
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestDiscountedOn(t *testing.T) {
+	at := date(2024, time.March, 1)
+
+	tests := []struct {
+		name      string
+		discounts []Discount
+		want      *big.Rat
+	}{
+		{
+			name: "no discount",
+			want: big.NewRat(100, 1),
+		},
+		{
+			name:      "percent",
+			discounts: []Discount{{Kind: DiscountPercent, Value: 10}},
+			want:      big.NewRat(90, 1),
+		},
+		{
+			name:      "fixed in minor units",
+			discounts: []Discount{{Kind: DiscountFixed, Value: 550}},
+			want:      big.NewRat(9450, 100),
+		},
+		{
+			name:      "percent before fixed",
+			discounts: []Discount{{Kind: DiscountFixed, Value: 1000}, {Kind: DiscountPercent, Value: 50}},
+			want:      big.NewRat(40, 1),
+		},
+		{
+			name:      "percentages compound",
+			discounts: []Discount{{Kind: DiscountPercent, Value: 50}, {Kind: DiscountPercent, Value: 50}},
+			want:      big.NewRat(25, 1),
+		},
+		{
+			name:      "never below zero",
+			discounts: []Discount{{Kind: DiscountFixed, Value: 15000}},
+			want:      new(big.Rat),
+		},
+		{
+			name:      "starting on the charge date",
+			discounts: []Discount{{Kind: DiscountPercent, Value: 10, StartDate: at}},
+			want:      big.NewRat(90, 1),
+		},
+		{
+			name:      "starting after the charge date",
+			discounts: []Discount{{Kind: DiscountPercent, Value: 10, StartDate: at.AddDate(0, 0, 1)}},
+			want:      big.NewRat(100, 1),
+		},
+		{
+			name:      "ending on the charge date",
+			discounts: []Discount{{Kind: DiscountPercent, Value: 10, EndDate: at}},
+			want:      big.NewRat(100, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{Currency: "USD", Discounts: tt.discounts}
+			amount := big.NewRat(100, 1)
+
+			got := sub.DiscountedOn(at, amount)
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("DiscountedOn() = %s, want %s", got.FloatString(2), tt.want.FloatString(2))
+			}
+			if amount.Cmp(big.NewRat(100, 1)) != 0 {
+				t.Errorf("DiscountedOn() changed the amount to %s", amount.FloatString(2))
+			}
+		})
+	}
+}
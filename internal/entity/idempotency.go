@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+// IdempotentRequest is a request a client made with an idempotency key, so
+// that a retry of it gets the response to the first attempt.
+type IdempotentRequest struct {
+	Operation    string    `db:"operation" json:"operation"` // method and path, a key is unique per operation
+	Key          string    `db:"key" json:"key"`
+	RequestHash  string    `db:"request_hash" json:"request_hash"`
+	StatusCode   int       `db:"status_code" json:"status_code"` // 0 until the first attempt is answered
+	ResponseBody []byte    `db:"response_body" json:"response_body"`
+	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// Completed reports whether the first attempt was answered.
+func (r *IdempotentRequest) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Money is an amount in the minor units of its currency, kopecks for RUB or
+// yen for JPY, so that fractions of the major unit are kept exactly. The
+// currency is held next to the amount, by the subscription, plan or budget.
+type Money uint64
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not a
+// hundredth of the major one.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+var amountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// CurrencyExponent returns the number of decimal places of the minor unit of currency.
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// ParseMoney parses a non-negative decimal amount in the major units of
+// currency, such as "299.99". It may not be more precise than the minor
+// unit, "0.5" yen is rejected rather than rounded.
+func ParseMoney(amount, currency string) (Money, error) {
+	if !amountPattern.MatchString(amount) {
+		return 0, ErrInvalidAmount
+	}
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	exponent := CurrencyExponent(currency)
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exponent {
+		return 0, ErrInvalidAmount
+	}
+
+	minor, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10)
+	if !ok || !minor.IsInt64() {
+		return 0, ErrInvalidAmount
+	}
+
+	return Money(minor.Int64()), nil
+}
+
+// Format formats the amount as a decimal in the major units of currency
+// with every decimal place of its minor unit, "299.90" rather than "299.9".
+func (m Money) Format(currency string) string {
+	return m.Rat(currency).FloatString(CurrencyExponent(currency))
+}
+
+// Rat returns the amount in the major units of currency.
+func (m Money) Rat(currency string) *big.Rat {
+	amount := new(big.Rat).SetUint64(uint64(m))
+	return amount.Quo(amount, new(big.Rat).SetInt(minorUnits(currency)))
+}
+
+// RoundMoney rounds a non-negative amount in the major units of currency half
+// up to its minor unit. Sums are to be rounded once, not term by term.
+func RoundMoney(amount *big.Rat, currency string) Money {
+	minor := new(big.Rat).Mul(amount, new(big.Rat).SetInt(minorUnits(currency)))
+	minor.Add(minor, big.NewRat(1, 2))
+
+	rounded := new(big.Int).Quo(minor.Num(), minor.Denom())
+	if !rounded.IsUint64() {
+		return math.MaxUint64
+	}
+	return Money(rounded.Uint64())
+}
+
+// minorUnits returns how many minor units of currency make up a major one.
+func minorUnits(currency string) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(CurrencyExponent(currency))), nil)
+}
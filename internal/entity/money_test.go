@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{amount: "299.99", currency: "RUB", want: 29999},
+		{amount: "299.9", currency: "RUB", want: 29990},
+		{amount: "299", currency: "RUB", want: 29900},
+		{amount: "0", currency: "RUB", want: 0},
+		{amount: "0.01", currency: "USD", want: 1},
+		{amount: "1.500", currency: "USD", want: 150},
+		{amount: "1500", currency: "JPY", want: 1500},
+		{amount: "1.0", currency: "JPY", want: 1},
+		{amount: "1.234", currency: "KWD", want: 1234},
+		{amount: "0.0001", currency: "CLF", want: 1},
+		{amount: "0.5", currency: "JPY", wantErr: ErrInvalidAmount},
+		{amount: "1.001", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "-1", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "1.", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: ".5", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "1e3", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "", currency: "USD", wantErr: ErrInvalidAmount},
+		{amount: "92233720368547758.08", currency: "USD", wantErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			got, err := ParseMoney(tt.amount, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseMoney() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		money    Money
+		currency string
+		want     string
+	}{
+		{money: 29990, currency: "RUB", want: "299.90"},
+		{money: 1, currency: "USD", want: "0.01"},
+		{money: 0, currency: "USD", want: "0.00"},
+		{money: 1500, currency: "JPY", want: "1500"},
+		{money: 1234, currency: "KWD", want: "1.234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want+" "+tt.currency, func(t *testing.T) {
+			if got := tt.money.Format(tt.currency); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   *big.Rat
+		currency string
+		want     Money
+	}{
+		{name: "exact", amount: big.NewRat(29999, 100), currency: "RUB", want: 29999},
+		{name: "half rounds up", amount: big.NewRat(1005, 1000), currency: "USD", want: 101},
+		{name: "below half rounds down", amount: big.NewRat(1004, 1000), currency: "USD", want: 100},
+		{name: "third", amount: big.NewRat(100, 3), currency: "USD", want: 3333},
+		{name: "two thirds", amount: big.NewRat(200, 3), currency: "USD", want: 6667},
+		{name: "no minor unit", amount: big.NewRat(3, 2), currency: "JPY", want: 2},
+		{name: "three decimals", amount: big.NewRat(1, 3), currency: "KWD", want: 333},
+		{name: "zero", amount: new(big.Rat), currency: "USD", want: 0},
+		{name: "overflow saturates", amount: new(big.Rat).SetFloat64(1e30), currency: "USD", want: math.MaxUint64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoundMoney(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("RoundMoney() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrPaymentMethodNotFound = errors.New("payment method not found")
+
+// PaymentMethodType is the kind of instrument a subscription is paid with.
+type PaymentMethodType string
+
+const (
+	PaymentCard   PaymentMethodType = "card"
+	PaymentWallet PaymentMethodType = "wallet"
+	PaymentBank   PaymentMethodType = "bank"
+)
+
+func (t PaymentMethodType) Valid() bool {
+	switch t {
+	case PaymentCard, PaymentWallet, PaymentBank:
+		return true
+	}
+	return false
+}
+
+// MaxPaymentMethodLabelLength is the longest payment method label in characters.
+const MaxPaymentMethodLabelLength = 64
+
+var lastFourPattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+// ValidLastFour reports whether s is the last four digits of a card or account number.
+func ValidLastFour(s string) bool {
+	return lastFourPattern.MatchString(s)
+}
+
+// PaymentMethod is a card, wallet or bank account a user pays subscriptions
+// with. Only the last four digits of its number are kept.
+type PaymentMethod struct {
+	Id        int64             `db:"id" json:"id"`
+	UserID    uuid.UUID         `db:"user_id" json:"user_id"`
+	Type      PaymentMethodType `db:"type" json:"type"`
+	Label     string            `db:"label" json:"label"`
+	LastFour  string            `db:"last_four" json:"last_four"`
+	CreatedAt time.Time         `db:"created_at" json:"created_at"`
+}
+
+// Masked returns the number of the payment method with all but its last
+// four digits hidden, or an empty string if they are not known.
+func (m *PaymentMethod) Masked() string {
+	if m.LastFour == "" {
+		return ""
+	}
+	return "**** " + m.LastFour
+}
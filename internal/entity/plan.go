@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPlanNotFound        = errors.New("plan not found")
+	ErrPlanExists          = errors.New("plan with this name already exists")
+	ErrPlanServiceMismatch = errors.New("plan belongs to another service")
+)
+
+// MaxPlanNameLength is the longest plan name in characters.
+const MaxPlanNameLength = 64
+
+// Plan is a tier of a catalog service, such as Basic or Premium, with the
+// price it is listed at. Subscriptions on a plan are charged its list price
+// unless they override it.
+type Plan struct {
+	Id                int64         `db:"id" json:"id"`
+	ServiceID         int64         `db:"service_id" json:"service_id"`
+	Name              string        `db:"name" json:"name"`
+	Price             Money         `db:"price" json:"price"`
+	Currency          string        `db:"currency" json:"currency"`
+	BillingPeriod     BillingPeriod `db:"billing_period" json:"billing_period"`
+	BillingPeriodDays int           `db:"billing_period_days" json:"billing_period_days"`
+	CreatedAt         time.Time     `db:"created_at" json:"created_at"`
+}
+
+// PlanUsage counts the subscriptions active on a plan and the users holding them.
+type PlanUsage struct {
+	Plan          Plan
+	ServiceName   string
+	Users         int
+	Subscriptions int
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPriceChangeBeforeStart = errors.New("price change is effective before the subscription starts")
+	ErrPriceChangeExists      = errors.New("price change with this effective date already exists")
+)
+
+// PriceChange sets a new subscription price from its effective date on.
+type PriceChange struct {
+	Id             int64     `db:"id" json:"id"`
+	SubscriptionID int64     `db:"subscription_id" json:"subscription_id"`
+	Price          Money     `db:"price" json:"price"`
+	Currency       string    `db:"currency" json:"currency"` // of the subscription
+	EffectiveDate  time.Time `db:"effective_date" json:"effective_date"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// PriceOn returns the price in effect at t. PriceChanges must be sorted by
+// effective date, before the first of them the subscription price applies.
+func (s *Subscription) PriceOn(t time.Time) Money {
+	price := s.Price
+	for _, change := range s.PriceChanges {
+		if change.EffectiveDate.After(t) {
+			break
+		}
+		price = change.Price
+	}
+	return price
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// NextRenewal returns the first charge on or after t that renews the
+// subscription: every charge after the start date, the one ending a free
+// trial included. It reports false for a fixed-term subscription and when
+// no charge is left.
+func (s *Subscription) NextRenewal(t time.Time) (time.Time, bool) {
+	if !s.AutoRenew {
+		return time.Time{}, false
+	}
+
+	if !t.After(s.StartDate) {
+		t = s.StartDate.Add(time.Nanosecond)
+	}
+
+	return s.NextBillingDate(t)
+}
+
+// CancellationDeadline returns the last day the subscription can be cancelled
+// without being renewed, the notice period before the first renewal that can
+// still be avoided on the day of t. It reports false when nothing renews.
+func (s *Subscription) CancellationDeadline(t time.Time) (time.Time, bool) {
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	for from := t; ; {
+		renewal, ok := s.NextRenewal(from)
+		if !ok {
+			return time.Time{}, false
+		}
+
+		deadline := renewal.AddDate(0, 0, -s.CancellationNoticeDays)
+		if !deadline.Before(today) {
+			return deadline, true
+		}
+		from = renewal.Add(time.Nanosecond)
+	}
+}
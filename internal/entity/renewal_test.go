@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancellationDeadline(t *testing.T) {
+	renewing := Subscription{
+		BillingPeriod:          BillingMonthly,
+		StartDate:              date(2024, time.January, 31),
+		AutoRenew:              true,
+		CancellationNoticeDays: 7,
+	}
+	withEnd := renewing
+	withEnd.EndDate = date(2024, time.March, 15)
+	fixedTerm := renewing
+	fixedTerm.AutoRenew = false
+	noNotice := renewing
+	noNotice.CancellationNoticeDays = 0
+
+	tests := []struct {
+		name   string
+		sub    Subscription
+		at     time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "notice before the next renewal",
+			sub:    renewing,
+			at:     date(2024, time.February, 10),
+			want:   date(2024, time.February, 22),
+			wantOK: true,
+		},
+		{
+			name:   "deadline is today",
+			sub:    renewing,
+			at:     date(2024, time.February, 22).Add(15 * time.Hour),
+			want:   date(2024, time.February, 22),
+			wantOK: true,
+		},
+		{
+			name:   "deadline missed moves to the renewal after",
+			sub:    renewing,
+			at:     date(2024, time.February, 25),
+			want:   date(2024, time.March, 24),
+			wantOK: true,
+		},
+		{
+			name:   "the start is not a renewal",
+			sub:    renewing,
+			at:     date(2024, time.January, 1),
+			want:   date(2024, time.February, 22),
+			wantOK: true,
+		},
+		{
+			name:   "no notice",
+			sub:    noNotice,
+			at:     date(2024, time.February, 10),
+			want:   date(2024, time.February, 29),
+			wantOK: true,
+		},
+		{
+			name: "no renewal before the end date",
+			sub:  withEnd,
+			at:   date(2024, time.March, 1),
+		},
+		{
+			name: "fixed term",
+			sub:  fixedTerm,
+			at:   date(2024, time.February, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.sub.CancellationDeadline(tt.at)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("CancellationDeadline() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
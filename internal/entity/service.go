@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrServiceNotFound = errors.New("service not found")
+	ErrServiceExists   = errors.New("service with this name or alias already exists")
+	ErrServiceInUse    = errors.New("service is in use by subscriptions")
+)
+
+// MaxServiceNameLength is the longest service name or alias in characters.
+const MaxServiceNameLength = 255
+
+// Service is a catalog entry subscriptions refer to. Every spelling of the
+// service, such as "yandex plus" or "яндекс плюс", is one of its aliases.
+type Service struct {
+	Id        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Aliases   []string  `db:"-" json:"aliases"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// NormalizeServiceName trims a service name and collapses the whitespace
+// within it.
+func NormalizeServiceName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NormalizeServiceAlias lowercases a normalized service name so that
+// "Yandex  Plus" and "yandex plus" resolve to the same service.
+func NormalizeServiceAlias(name string) string {
+	return strings.ToLower(NormalizeServiceName(name))
+}
+
+// ValidServiceName reports whether a normalized name can be used for a
+// service or an alias.
+func ValidServiceName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= MaxServiceNameLength
+}
+
+// ServiceAliases normalizes the aliases of a service, adds its own name to
+// them and drops duplicates.
+func ServiceAliases(name string, aliases []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, alias := range append([]string{name}, aliases...) {
+		alias = NormalizeServiceAlias(alias)
+		if alias == "" || seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		normalized = append(normalized, alias)
+	}
+	return normalized
+}
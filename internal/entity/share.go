@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"math/big"
+
+	"github.com/google/uuid"
+)
+
+// Share makes a user a member of a subscription paid by someone else. A
+// member pays either a fixed amount of every charge or a weighted part of
+// what is left once the fixed amounts are taken out.
+type Share struct {
+	SubscriptionID int64     `db:"subscription_id" json:"subscription_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	Weight         uint64    `db:"weight" json:"weight"`
+	FixedAmount    *Money    `db:"fixed_amount" json:"fixed_amount"`
+	Currency       string    `db:"currency" json:"currency"` // of the subscription, the fixed amount is in
+}
+
+// Members returns the owner followed by every other user sharing the subscription.
+func (s *Subscription) Members() []uuid.UUID {
+	members := []uuid.UUID{s.UserID}
+	for _, share := range s.Shares {
+		if share.UserID != s.UserID {
+			members = append(members, share.UserID)
+		}
+	}
+	return members
+}
+
+// ShareOf returns the part of a charge of the given amount, in the major
+// units of the subscription currency, paid by userID.
+// Fixed amounts are taken out in the order of the shares for as long as the
+// charge covers them, the owner pays whatever the shares leave uncovered.
+func (s *Subscription) ShareOf(userID uuid.UUID, amount *big.Rat) *big.Rat {
+	if len(s.Shares) == 0 {
+		if userID == s.UserID {
+			return new(big.Rat).Set(amount)
+		}
+		return new(big.Rat)
+	}
+
+	remainder := new(big.Rat).Set(amount)
+	var weights uint64
+	var own *Share
+	var ownFixed *big.Rat
+	for i, share := range s.Shares {
+		if share.UserID == userID {
+			own = &s.Shares[i]
+		}
+		if share.FixedAmount == nil {
+			weights += share.Weight
+			continue
+		}
+
+		fixed := share.FixedAmount.Rat(s.Currency)
+		if fixed.Cmp(remainder) > 0 {
+			fixed.Set(remainder)
+		}
+		remainder.Sub(remainder, fixed)
+		if share.UserID == userID {
+			ownFixed = fixed
+		}
+	}
+
+	switch {
+	case ownFixed != nil:
+		return ownFixed
+	case own != nil && weights > 0:
+		part := big.NewRat(int64(own.Weight), int64(weights))
+		return part.Mul(part, remainder)
+	case userID == s.UserID && weights == 0:
+		return remainder
+	}
+
+	return new(big.Rat)
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestShareOf(t *testing.T) {
+	owner, alice, bob, carol := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	fixed := func(m Money) *Money { return &m }
+
+	tests := []struct {
+		name   string
+		shares []Share
+		want   map[uuid.UUID]*big.Rat
+	}{
+		{
+			name: "not shared",
+			want: map[uuid.UUID]*big.Rat{owner: big.NewRat(100, 1), alice: new(big.Rat)},
+		},
+		{
+			name:   "weighted",
+			shares: []Share{{UserID: alice, Weight: 1}, {UserID: bob, Weight: 3}},
+			want:   map[uuid.UUID]*big.Rat{owner: new(big.Rat), alice: big.NewRat(25, 1), bob: big.NewRat(75, 1)},
+		},
+		{
+			name:   "fixed and weighted",
+			shares: []Share{{UserID: alice, FixedAmount: fixed(3000)}, {UserID: bob, Weight: 1}},
+			want:   map[uuid.UUID]*big.Rat{owner: new(big.Rat), alice: big.NewRat(30, 1), bob: big.NewRat(70, 1)},
+		},
+		{
+			name:   "owner pays what fixed shares leave",
+			shares: []Share{{UserID: alice, FixedAmount: fixed(3000)}},
+			want:   map[uuid.UUID]*big.Rat{owner: big.NewRat(70, 1), alice: big.NewRat(30, 1)},
+		},
+		{
+			name:   "fixed share above the charge",
+			shares: []Share{{UserID: alice, FixedAmount: fixed(12000)}},
+			want:   map[uuid.UUID]*big.Rat{owner: new(big.Rat), alice: big.NewRat(100, 1)},
+		},
+		{
+			name:   "fixed shares together above the charge",
+			shares: []Share{{UserID: alice, FixedAmount: fixed(8000)}, {UserID: bob, FixedAmount: fixed(5000)}, {UserID: carol, Weight: 1}},
+			want:   map[uuid.UUID]*big.Rat{owner: new(big.Rat), alice: big.NewRat(80, 1), bob: big.NewRat(20, 1), carol: new(big.Rat)},
+		},
+		{
+			name:   "not a member",
+			shares: []Share{{UserID: alice, Weight: 1}},
+			want:   map[uuid.UUID]*big.Rat{owner: new(big.Rat), alice: big.NewRat(100, 1), bob: new(big.Rat)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{UserID: owner, Currency: "USD", Shares: tt.shares}
+			amount := big.NewRat(100, 1)
+
+			for userID, want := range tt.want {
+				if got := sub.ShareOf(userID, amount); got.Cmp(want) != 0 {
+					t.Errorf("ShareOf(%s) = %s, want %s", userID, got.FloatString(2), want.FloatString(2))
+				}
+			}
+
+			total := new(big.Rat)
+			for _, member := range sub.Members() {
+				total.Add(total, sub.ShareOf(member, amount))
+			}
+			if total.Cmp(amount) != 0 {
+				t.Errorf("shares add up to %s, want %s", total.FloatString(2), amount.FloatString(2))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid subscription status transition")
+	ErrSubscriptionNotStarted  = errors.New("subscription has not started yet")
+)
+
+// Status is the lifecycle state of a subscription.
+type Status string
+
+const (
+	StatusTrial     Status = "trial"
+	StatusActive    Status = "active"
+	StatusPaused    Status = "paused"
+	StatusCancelled Status = "cancelled"
+	StatusExpired   Status = "expired"
+)
+
+// statusTransitions lists the states every state may move to.
+var statusTransitions = map[Status][]Status{
+	StatusTrial:  {StatusActive, StatusPaused, StatusCancelled},
+	StatusActive: {StatusPaused, StatusCancelled},
+	StatusPaused: {StatusActive, StatusCancelled},
+}
+
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Pause is an interval in which a subscription is neither used nor charged.
+// A zero EndDate means the subscription has not been resumed yet.
+type Pause struct {
+	Id             int64     `db:"id" json:"id"`
+	SubscriptionID int64     `db:"subscription_id" json:"subscription_id"`
+	StartDate      time.Time `db:"start_date" json:"start_date"`
+	EndDate        time.Time `db:"end_date" json:"end_date"`
+}
+
+// StatusAt returns the status at t. Subscriptions past their end date are
+// expired and trials past their end are active even if nothing changed
+// their stored status.
+func (s *Subscription) StatusAt(t time.Time) Status {
+	if s.Status != StatusCancelled && !s.EndDate.IsZero() && !t.Before(s.EndDate) {
+		return StatusExpired
+	}
+	if s.Status == StatusTrial && !t.Before(s.TrialEndDate) {
+		return StatusActive
+	}
+	return s.Status
+}
+
+// PausedAt reports whether t falls within one of the subscription pauses.
+func (s *Subscription) PausedAt(t time.Time) bool {
+	for _, pause := range s.Pauses {
+		if !t.Before(pause.StartDate) && (pause.EndDate.IsZero() || t.Before(pause.EndDate)) {
+			return true
+		}
+	}
+	return false
+}
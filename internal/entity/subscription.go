@@ -1,18 +1,75 @@
 package entity
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrSubscriptionExists   = errors.New("subscription to this service starting on this date already exists for the user")
+	ErrVersionMismatch      = errors.New("subscription was modified since it was read")
+	ErrSubscriptionEnded    = errors.New("end date of a cancelled or expired subscription cannot be changed")
+	ErrTooManySubscriptions = errors.New("too many subscriptions match to filter or sort them by next billing date")
+)
+
 type Subscription struct {
-    Id          int64     `db:"id" json:"id"`
-    ServiceName string    `db:"service_name" json:"service_name"`
-    Price       uint64    `db:"price" json:"price"`
-    UserID      uuid.UUID `db:"user_id" json:"user_id"`
-    StartDate   time.Time `db:"start_date" json:"start_date"`
-	EndDate  time.Time `db:"end_date" json:"end_date"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	Id                     int64             `db:"id" json:"id"`
+	ServiceID              int64             `db:"service_id" json:"service_id"`
+	ServiceName            string            `db:"service_name" json:"service_name"`
+	PlanID                 int64             `db:"plan_id" json:"plan_id"`
+	Price                  Money             `db:"price" json:"price"`
+	Currency               string            `db:"currency" json:"currency"`
+	BillingPeriod          BillingPeriod     `db:"billing_period" json:"billing_period"`
+	BillingPeriodDays      int               `db:"billing_period_days" json:"billing_period_days"`
+	Status                 Status            `db:"status" json:"status"`
+	UserID                 uuid.UUID         `db:"user_id" json:"user_id"`
+	StartDate              time.Time         `db:"start_date" json:"start_date"`
+	EndDate                time.Time         `db:"end_date" json:"end_date"`
+	TrialStartDate         time.Time         `db:"trial_start_date" json:"trial_start_date"`
+	TrialEndDate           time.Time         `db:"trial_end_date" json:"trial_end_date"`
+	TaxRegion              string            `db:"tax_region" json:"tax_region"`
+	TaxInclusive           bool              `db:"tax_inclusive" json:"tax_inclusive"`
+	PaymentMethodID        int64             `db:"payment_method_id" json:"payment_method_id"`
+	AutoRenew              bool              `db:"auto_renew" json:"auto_renew"`
+	CancellationNoticeDays int               `db:"cancellation_notice_days" json:"cancellation_notice_days"`
+	Notes                  string            `db:"notes" json:"notes"`
+	Attributes             map[string]string `db:"attributes" json:"attributes"`
+	CreatedAt              time.Time         `db:"created_at" json:"created_at"`
+	Version                int64             `db:"version" json:"version"` // bumped on every update
+
+	PriceChanges []PriceChange `db:"-" json:"price_changes,omitempty"`
+	Pauses       []Pause       `db:"-" json:"pauses,omitempty"`
+	Shares       []Share       `db:"-" json:"shares,omitempty"`
+	Discounts    []Discount    `db:"-" json:"discounts,omitempty"`
+	Tags         []Tag         `db:"-" json:"tags,omitempty"`
 }
 
+// ReplaceWith replaces s with sub, keeping the identity, version and history
+// of s along with the status of a paused, cancelled or expired one. The end
+// date of one that is cancelled or expired at at is where its billing stops,
+// changing it fails with ErrSubscriptionEnded.
+func (s *Subscription) ReplaceWith(sub *Subscription, at time.Time) error {
+	if status := s.StatusAt(at); (status == StatusCancelled || status == StatusExpired) && !sub.EndDate.Equal(s.EndDate) {
+		return ErrSubscriptionEnded
+	}
+
+	sub.Id = s.Id
+	sub.CreatedAt = s.CreatedAt
+	sub.Version = s.Version
+	if s.Status != StatusActive && s.Status != StatusTrial {
+		sub.Status = s.Status
+	}
+
+	sub.PriceChanges = s.PriceChanges
+	sub.Pauses = s.Pauses
+	sub.Shares = s.Shares
+	sub.Discounts = s.Discounts
+	sub.Tags = s.Tags
+
+	*s = *sub
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplaceWith(t *testing.T) {
+	end := date(2024, time.June, 1)
+
+	tests := []struct {
+		name       string
+		status     Status
+		endDate    time.Time
+		newEndDate time.Time
+		wantStatus Status
+		wantErr    error
+	}{
+		{name: "active takes the new status", status: StatusActive, newEndDate: end, wantStatus: StatusTrial},
+		{name: "paused keeps its status", status: StatusPaused, newEndDate: end, wantStatus: StatusPaused},
+		{name: "cancelled keeps its end date", status: StatusCancelled, endDate: end, newEndDate: end, wantStatus: StatusCancelled},
+		{name: "cancelled end date cleared", status: StatusCancelled, endDate: end, wantErr: ErrSubscriptionEnded},
+		{name: "expired end date moved", status: StatusExpired, endDate: end, newEndDate: end.AddDate(0, 1, 0), wantErr: ErrSubscriptionEnded},
+		{name: "active past its end date cleared", status: StatusActive, endDate: date(2024, time.February, 1), wantErr: ErrSubscriptionEnded},
+		{name: "active before its end date moved", status: StatusActive, endDate: end, newEndDate: end.AddDate(0, 1, 0), wantStatus: StatusTrial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{
+				Id:      7,
+				Version: 3,
+				Status:  tt.status,
+				EndDate: tt.endDate,
+				Pauses:  []Pause{{StartDate: date(2024, time.January, 1)}},
+			}
+			replacement := &Subscription{Status: StatusTrial, EndDate: tt.newEndDate, Notes: "replaced"}
+
+			err := sub.ReplaceWith(replacement, date(2024, time.March, 1))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReplaceWith() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if sub.Notes != "" {
+					t.Errorf("ReplaceWith() replaced the subscription despite failing")
+				}
+				return
+			}
+
+			if sub.Id != 7 || sub.Version != 3 || len(sub.Pauses) != 1 {
+				t.Errorf("ReplaceWith() lost the identity or history: %+v", sub)
+			}
+			if sub.Status != tt.wantStatus || sub.Notes != "replaced" {
+				t.Errorf("ReplaceWith() status = %s, notes = %q, want %s, %q", sub.Status, sub.Notes, tt.wantStatus, "replaced")
+			}
+		})
+	}
+}
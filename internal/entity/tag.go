@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag with this name already exists")
+)
+
+// MaxTagNameLength is the longest tag name in characters.
+const MaxTagNameLength = 64
+
+// Tag labels subscriptions with a category such as streaming or work.
+type Tag struct {
+	Id        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// NormalizeTagName trims and lowercases a tag name so that "Dev Tools" and
+// "dev tools" are the same tag.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ValidTagName reports whether a normalized name can be used for a tag. Tag
+// names are listed comma separated in filters, so they cannot hold a comma.
+func ValidTagName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= MaxTagNameLength && !strings.Contains(name, ",")
+}
+
+// TagNames returns the names of the subscription tags.
+func (s *Subscription) TagNames() []string {
+	names := make([]string, len(s.Tags))
+	for i, tag := range s.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+)
+
+var ErrTaxRegionNotFound = errors.New("tax region not found")
+
+var taxRegionPattern = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+
+// ValidTaxRegion reports whether region looks like an ISO 3166 country or
+// subdivision code, such as DE or US-CA.
+func ValidTaxRegion(region string) bool {
+	return taxRegionPattern.MatchString(region)
+}
+
+// SplitTax splits an amount charged for the subscription into its net part
+// and the tax at the given rate. A tax inclusive price already holds the
+// tax, an exclusive one has it added on top.
+func (s *Subscription) SplitTax(amount, rate *big.Rat) (net, tax *big.Rat) {
+	if s.TaxInclusive {
+		net = new(big.Rat).Quo(amount, new(big.Rat).Add(big.NewRat(1, 1), rate))
+		tax = new(big.Rat).Sub(amount, net)
+		return net, tax
+	}
+
+	return new(big.Rat).Set(amount), new(big.Rat).Mul(amount, rate)
+}
@@ -2,16 +2,90 @@ package repo
 
 import (
 	"context"
+	"math/big"
+	"time"
 
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo/persistence"
+	"github.com/google/uuid"
 )
 
 type SubscriptionRepo interface {
 	Store(cxt context.Context, sub *entity.Subscription) error
 	Get(cxt context.Context, id int) (*entity.Subscription, error)
 	Update(cxt context.Context, sub *entity.Subscription) error
-	Delete(cxt context.Context, id int) error
+	Delete(cxt context.Context, id int, versions ...int64) error
 	List(cxt context.Context, opts ...persistence.ListOption) ([]*entity.Subscription, error)
-	GetTotalCost(ctx context.Context, userID *string, serviceName *string, startDate, endDate string) (uint64, error)
+	Count(ctx context.Context, opts ...persistence.ListOption) (int, error)
+	// Exists reports whether the user has a subscription to the service
+	// starting at startDate, which the unique_subscription constraint forbids
+	// a second of.
+	Exists(ctx context.Context, userID uuid.UUID, serviceID int64, startDate time.Time) (bool, error)
+	StorePriceChange(ctx context.Context, change *entity.PriceChange) error
+	ListPriceChanges(ctx context.Context, subscriptionIDs ...int64) ([]entity.PriceChange, error)
+	StorePause(ctx context.Context, pause *entity.Pause) error
+	ClosePause(ctx context.Context, subscriptionID int64, end time.Time) error
+	ListPauses(ctx context.Context, subscriptionIDs ...int64) ([]entity.Pause, error)
+	ReplaceShares(ctx context.Context, subscriptionID int64, shares []entity.Share) error
+	ListShares(ctx context.Context, subscriptionIDs ...int64) ([]entity.Share, error)
+	StoreDiscount(ctx context.Context, discount *entity.Discount) error
+	GetDiscount(ctx context.Context, subscriptionID, id int64) (*entity.Discount, error)
+	UpdateDiscount(ctx context.Context, discount *entity.Discount) error
+	DeleteDiscount(ctx context.Context, subscriptionID, id int64) error
+	ListDiscounts(ctx context.Context, subscriptionIDs ...int64) ([]entity.Discount, error)
+	StoreTag(ctx context.Context, tag *entity.Tag) error
+	GetTag(ctx context.Context, id int64) (*entity.Tag, error)
+	UpdateTag(ctx context.Context, tag *entity.Tag) error
+	DeleteTag(ctx context.Context, id int64) error
+	ListTags(ctx context.Context) ([]entity.Tag, error)
+	ReplaceSubscriptionTags(ctx context.Context, subscriptionID int64, names []string) ([]entity.Tag, error)
+	ListSubscriptionTags(ctx context.Context, subscriptionIDs ...int64) (map[int64][]entity.Tag, error)
+	StoreService(ctx context.Context, service *entity.Service) error
+	GetService(ctx context.Context, id int64) (*entity.Service, error)
+	FindService(ctx context.Context, name string) (*entity.Service, error)
+	UpdateService(ctx context.Context, service *entity.Service) error
+	DeleteService(ctx context.Context, id int64) error
+	ListServices(ctx context.Context) ([]entity.Service, error)
+	MergeServices(ctx context.Context, id int64, duplicateIDs []int64) error
+	StorePlan(ctx context.Context, plan *entity.Plan) error
+	GetPlan(ctx context.Context, serviceID, id int64) (*entity.Plan, error)
+	UpdatePlan(ctx context.Context, plan *entity.Plan) error
+	DeletePlan(ctx context.Context, serviceID, id int64) error
+	ListPlans(ctx context.Context, serviceID int64) ([]entity.Plan, error)
+	PlanUsage(ctx context.Context, serviceID *int64, on time.Time) ([]entity.PlanUsage, error)
+	StorePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error
+	GetPaymentMethod(ctx context.Context, userID uuid.UUID, id int64) (*entity.PaymentMethod, error)
+	UpdatePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error
+	DeletePaymentMethod(ctx context.Context, userID uuid.UUID, id int64) error
+	ListPaymentMethods(ctx context.Context, userID uuid.UUID) ([]entity.PaymentMethod, error)
+	StoreBudget(ctx context.Context, budget *entity.Budget) error
+	GetBudget(ctx context.Context, userID uuid.UUID, id int64) (*entity.Budget, error)
+	UpdateBudget(ctx context.Context, budget *entity.Budget) error
+	DeleteBudget(ctx context.Context, userID uuid.UUID, id int64) error
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]entity.Budget, error)
+	// WithinTx runs fn in a transaction shared by the calls made with its ctx.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ExchangeRateProvider converts amounts between currencies.
+type ExchangeRateProvider interface {
+	// Rate returns how many units of quote one unit of base is worth on the
+	// given date, or entity.ErrExchangeRateNotFound.
+	Rate(ctx context.Context, base, quote string, on time.Time) (*big.Rat, error)
+}
+
+// TaxRateProvider serves the tax rates of regions.
+type TaxRateProvider interface {
+	// TaxRate returns the rate of the region as a fraction of the net amount,
+	// or entity.ErrTaxRegionNotFound.
+	TaxRate(ctx context.Context, region string) (*big.Rat, error)
+}
+
+// IdempotencyStore keeps the responses to requests made with an idempotency key.
+type IdempotencyStore interface {
+	// Reserve records req as in progress, or returns the request already
+	// stored under its key.
+	Reserve(ctx context.Context, req *entity.IdempotentRequest) (*entity.IdempotentRequest, error)
+	Complete(ctx context.Context, req *entity.IdempotentRequest) error
+	Release(ctx context.Context, req *entity.IdempotentRequest) error
 }
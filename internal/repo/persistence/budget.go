@@ -0,0 +1,207 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var budgetColumns = []string{
+	"b.id", "b.user_id", "b.monthly_limit", "b.currency", "COALESCE(t.name, '')", "COALESCE(b.service_name, '')", "b.created_at",
+}
+
+// scanBudget reads a row selected with budgetColumns.
+func scanBudget(row pgx.Row) (entity.Budget, error) {
+	var budget entity.Budget
+	err := row.Scan(
+		&budget.Id, &budget.UserID, &budget.MonthlyLimit, &budget.Currency, &budget.Tag, &budget.ServiceName, &budget.CreatedAt,
+	)
+	return budget, err
+}
+
+func (r *SubscriptionRepo) selectBudgets() squirrel.SelectBuilder {
+	return r.Builder.
+		Select(budgetColumns...).
+		From("budgets b").
+		LeftJoin("tags t ON t.id = b.tag_id")
+}
+
+func (r *SubscriptionRepo) StoreBudget(ctx context.Context, budget *entity.Budget) error {
+	const op = "subscriptionRepo.StoreBudget"
+
+	tagID, err := r.budgetTagID(ctx, budget.Tag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	sql, args, err := r.Builder.
+		Insert("budgets").
+		Columns("user_id", "monthly_limit", "currency", "tag_id", "service_name").
+		Values(budget.UserID, budget.MonthlyLimit, budget.Currency, tagID, nullString(budget.ServiceName)).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&budget.Id, &budget.CreatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrBudgetExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) GetBudget(ctx context.Context, userID uuid.UUID, id int64) (*entity.Budget, error) {
+	const op = "subscriptionRepo.GetBudget"
+
+	sql, args, err := r.selectBudgets().
+		Where(squirrel.Eq{"b.id": id, "b.user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	budget, err := scanBudget(r.Querier(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrBudgetNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return &budget, nil
+}
+
+func (r *SubscriptionRepo) UpdateBudget(ctx context.Context, budget *entity.Budget) error {
+	const op = "subscriptionRepo.UpdateBudget"
+
+	tagID, err := r.budgetTagID(ctx, budget.Tag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	sql, args, err := r.Builder.
+		Update("budgets").
+		Set("monthly_limit", budget.MonthlyLimit).
+		Set("currency", budget.Currency).
+		Set("tag_id", tagID).
+		Set("service_name", nullString(budget.ServiceName)).
+		Where(squirrel.Eq{"id": budget.Id, "user_id": budget.UserID}).
+		Suffix("RETURNING created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&budget.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, entity.ErrBudgetNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrBudgetExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) DeleteBudget(ctx context.Context, userID uuid.UUID, id int64) error {
+	const op = "subscriptionRepo.DeleteBudget"
+
+	sql, args, err := r.Builder.
+		Delete("budgets").
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrBudgetNotFound)
+	}
+
+	return nil
+}
+
+// ListBudgets returns the budgets of a user ordered by id.
+func (r *SubscriptionRepo) ListBudgets(ctx context.Context, userID uuid.UUID) ([]entity.Budget, error) {
+	const op = "subscriptionRepo.ListBudgets"
+
+	sql, args, err := r.selectBudgets().
+		Where(squirrel.Eq{"b.user_id": userID}).
+		OrderBy("b.id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var budgets []entity.Budget
+	for rows.Next() {
+		budget, err := scanBudget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return budgets, nil
+}
+
+// budgetTagID looks up the tag a budget is limited to, nil if it is not.
+func (r *SubscriptionRepo) budgetTagID(ctx context.Context, name string) (any, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	sql, args, err := r.Builder.
+		Select("id").
+		From("tags").
+		Where(squirrel.Eq{"name": name}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var id int64
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrTagNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+
+	return id, nil
+}
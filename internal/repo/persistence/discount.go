@@ -0,0 +1,163 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var discountColumns = []string{
+	"id", "subscription_id", "kind", "value", subscriptionCurrencyColumn,
+	"COALESCE(start_date, '0001-01-01'::timestamptz)", "COALESCE(end_date, '0001-01-01'::timestamptz)", "created_at",
+}
+
+// scanDiscount reads a row selected with discountColumns.
+func scanDiscount(row pgx.Row) (entity.Discount, error) {
+	var discount entity.Discount
+	err := row.Scan(
+		&discount.Id, &discount.SubscriptionID, &discount.Kind, &discount.Value, &discount.Currency,
+		&discount.StartDate, &discount.EndDate, &discount.CreatedAt,
+	)
+	return discount, err
+}
+
+func (r *SubscriptionRepo) StoreDiscount(ctx context.Context, discount *entity.Discount) error {
+	const op = "subscriptionRepo.StoreDiscount"
+	sql, args, err := r.Builder.
+		Insert("subscription_discounts").
+		Columns("subscription_id", "kind", "value", "start_date", "end_date").
+		Values(discount.SubscriptionID, discount.Kind, discount.Value, nullTime(discount.StartDate), nullTime(discount.EndDate)).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&discount.Id, &discount.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) GetDiscount(ctx context.Context, subscriptionID, id int64) (*entity.Discount, error) {
+	const op = "subscriptionRepo.GetDiscount"
+	sql, args, err := r.Builder.
+		Select(discountColumns...).
+		From("subscription_discounts").
+		Where(squirrel.Eq{"id": id, "subscription_id": subscriptionID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	discount, err := scanDiscount(r.Querier(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrDiscountNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return &discount, nil
+}
+
+func (r *SubscriptionRepo) UpdateDiscount(ctx context.Context, discount *entity.Discount) error {
+	const op = "subscriptionRepo.UpdateDiscount"
+
+	sql, args, err := r.Builder.
+		Update("subscription_discounts").
+		Set("kind", discount.Kind).
+		Set("value", discount.Value).
+		Set("start_date", nullTime(discount.StartDate)).
+		Set("end_date", nullTime(discount.EndDate)).
+		Where(squirrel.Eq{"id": discount.Id, "subscription_id": discount.SubscriptionID}).
+		Suffix("RETURNING created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&discount.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, entity.ErrDiscountNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) DeleteDiscount(ctx context.Context, subscriptionID, id int64) error {
+	const op = "subscriptionRepo.DeleteDiscount"
+
+	sql, args, err := r.Builder.
+		Delete("subscription_discounts").
+		Where(squirrel.Eq{"id": id, "subscription_id": subscriptionID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrDiscountNotFound)
+	}
+
+	return nil
+}
+
+// ListDiscounts returns the discounts of the given subscriptions ordered by id.
+func (r *SubscriptionRepo) ListDiscounts(ctx context.Context, subscriptionIDs ...int64) ([]entity.Discount, error) {
+	const op = "subscriptionRepo.ListDiscounts"
+
+	if len(subscriptionIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, err := r.Builder.
+		Select(discountColumns...).
+		From("subscription_discounts").
+		Where(squirrel.Eq{"subscription_id": subscriptionIDs}).
+		OrderBy("id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var discounts []entity.Discount
+	for rows.Next() {
+		discount, err := scanDiscount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		discounts = append(discounts, discount)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return discounts, nil
+}
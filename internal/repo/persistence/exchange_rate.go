@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExchangeRateRepo serves exchange rates from the local exchange_rates table.
+type ExchangeRateRepo struct {
+	*postgres.Postgres
+}
+
+func NewExchangeRateRepo(pg *postgres.Postgres) *ExchangeRateRepo {
+	return &ExchangeRateRepo{
+		pg,
+	}
+}
+
+// Rate returns the latest base/quote rate effective on the given date.
+func (r *ExchangeRateRepo) Rate(ctx context.Context, base, quote string, on time.Time) (*big.Rat, error) {
+	const op = "exchangeRateRepo.Rate"
+
+	if base == quote {
+		return big.NewRat(1, 1), nil
+	}
+
+	rate, err := r.latestRate(ctx, base, quote, on)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %s to %s on %s: %w", op, base, quote, on.Format("2006-01-02"), entity.ErrExchangeRateNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return rate, nil
+}
+
+func (r *ExchangeRateRepo) latestRate(ctx context.Context, base, quote string, on time.Time) (*big.Rat, error) {
+	sql, args, err := r.Builder.
+		Select("rate::text").
+		From("exchange_rates").
+		Where(squirrel.Eq{"base_currency": base, "quote_currency": quote}).
+		Where(squirrel.LtOrEq{"effective_date": on}).
+		OrderBy("effective_date DESC").
+		Limit(1).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var value string
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&value)
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid rate %q", value)
+	}
+
+	return rate, nil
+}
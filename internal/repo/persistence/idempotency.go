@@ -0,0 +1,133 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// IdempotencyRepo keeps the responses to idempotent requests in the
+// idempotency_keys table until they expire.
+type IdempotencyRepo struct {
+	*postgres.Postgres
+}
+
+func NewIdempotencyRepo(pg *postgres.Postgres) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		pg,
+	}
+}
+
+// Reserve records req as in progress unless its key is taken, in which case
+// the request stored under the key is returned. Expired keys are purged first.
+func (r *IdempotencyRepo) Reserve(ctx context.Context, req *entity.IdempotentRequest) (*entity.IdempotentRequest, error) {
+	const op = "idempotencyRepo.Reserve"
+
+	sql, args, err := r.Builder.
+		Delete("idempotency_keys").
+		Where("expires_at <= NOW()").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	sql, args, err = r.Builder.
+		Insert("idempotency_keys").
+		Columns("operation", "key", "request_hash", "expires_at").
+		Values(req.Operation, req.Key, req.RequestHash, req.ExpiresAt).
+		Suffix("ON CONFLICT (operation, key) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	if result.RowsAffected() > 0 {
+		return nil, nil
+	}
+
+	sql, args, err = r.Builder.
+		Select("operation", "key", "request_hash", "COALESCE(status_code, 0)", "COALESCE(response_body, ''::bytea)", "expires_at").
+		From("idempotency_keys").
+		Where(squirrel.Eq{"operation": req.Operation, "key": req.Key}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	stored := &entity.IdempotentRequest{}
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(
+		&stored.Operation, &stored.Key, &stored.RequestHash, &stored.StatusCode, &stored.ResponseBody, &stored.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// the first attempt failed and gave up the key meanwhile
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrIdempotencyKeyInProgress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return stored, nil
+}
+
+// Complete stores the response to a reserved request.
+func (r *IdempotencyRepo) Complete(ctx context.Context, req *entity.IdempotentRequest) error {
+	const op = "idempotencyRepo.Complete"
+
+	sql, args, err := r.Builder.
+		Update("idempotency_keys").
+		Set("status_code", req.StatusCode).
+		Set("response_body", req.ResponseBody).
+		Where(squirrel.Eq{"operation": req.Operation, "key": req.Key}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+// Release gives up the key of a reserved request, so that a retry runs it
+// anew.
+func (r *IdempotencyRepo) Release(ctx context.Context, req *entity.IdempotentRequest) error {
+	const op = "idempotencyRepo.Release"
+
+	sql, args, err := r.Builder.
+		Delete("idempotency_keys").
+		Where(squirrel.Eq{"operation": req.Operation, "key": req.Key}).
+		Where("status_code IS NULL").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
@@ -3,21 +3,31 @@ package persistence
 import (
 	"time"
 
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
 	"github.com/google/uuid"
 )
 
 type ListOption func(*ListOptions)
 
 type ListOptions struct {
-	UserID        *uuid.UUID
-	ServiceName   *string
-	Price         *uint64
-	StartDateFrom *time.Time
-	StartDateTo   *time.Time
-	Limit         int
-	Offset        int
-	SortBy        string
-	SortOrder     string
+	UserID                   *uuid.UUID
+	ServiceName              *string
+	Price                    *entity.Money
+	StartDateFrom            *time.Time
+	StartDateTo              *time.Time
+	ActiveFrom               *time.Time
+	ActiveTo                 *time.Time
+	TrialEndFrom             *time.Time
+	TrialEndTo               *time.Time
+	CancellationDeadlineFrom *time.Time
+	CancellationDeadlineTo   *time.Time
+	Tags                     []string
+	Attributes               map[string]string
+	PaymentMethodID          *int64
+	Limit                    int
+	Offset                   int
+	SortBy                   string
+	SortOrder                string
 }
 
 func WithUserID(id uuid.UUID) ListOption {
@@ -32,7 +42,7 @@ func WithServiceName(name string) ListOption {
 	}
 }
 
-func WithPrice(price uint64) ListOption {
+func WithPrice(price entity.Money) ListOption {
 	return func(l *ListOptions) {
 		l.Price = &price
 	}
@@ -49,3 +59,85 @@ func WithStartDateTo(t time.Time) ListOption {
 		l.StartDateTo = &t
 	}
 }
+
+// WithActiveBetween keeps subscriptions that are active at some point in [from, to).
+func WithActiveBetween(from, to time.Time) ListOption {
+	return func(l *ListOptions) {
+		l.ActiveFrom = &from
+		l.ActiveTo = &to
+	}
+}
+
+// WithTags keeps subscriptions labelled with any of the named tags.
+func WithTags(names ...string) ListOption {
+	return func(l *ListOptions) {
+		l.Tags = names
+	}
+}
+
+// WithAttribute keeps subscriptions whose custom attribute key holds value,
+// every attribute given has to match.
+func WithAttribute(key, value string) ListOption {
+	return func(l *ListOptions) {
+		if l.Attributes == nil {
+			l.Attributes = map[string]string{}
+		}
+		l.Attributes[key] = value
+	}
+}
+
+func WithPaymentMethod(id int64) ListOption {
+	return func(l *ListOptions) {
+		l.PaymentMethodID = &id
+	}
+}
+
+// WithLimit caps the number of returned rows, zero lifts the limit.
+func WithLimit(limit int) ListOption {
+	return func(l *ListOptions) {
+		l.Limit = limit
+	}
+}
+
+// WithOffset skips the first rows.
+func WithOffset(offset int) ListOption {
+	return func(l *ListOptions) {
+		l.Offset = offset
+	}
+}
+
+// WithSort orders the rows by a column, asc or desc, the caller has to make
+// sure the column is one of the subscription columns.
+func WithSort(by, order string) ListOption {
+	return func(l *ListOptions) {
+		l.SortBy = by
+		l.SortOrder = order
+	}
+}
+
+// WithTrialEndingWithin keeps subscriptions whose free trial ends within the next days.
+func WithTrialEndingWithin(days int) ListOption {
+	return func(l *ListOptions) {
+		now := time.Now()
+		to := now.AddDate(0, 0, days)
+		l.TrialEndFrom = &now
+		l.TrialEndTo = &to
+	}
+}
+
+// WithCancellationDeadlineBetween keeps auto-renewing subscriptions that
+// have to be cancelled within [from, to) to avoid one of their renewals.
+func WithCancellationDeadlineBetween(from, to time.Time) ListOption {
+	return func(l *ListOptions) {
+		l.CancellationDeadlineFrom = &from
+		l.CancellationDeadlineTo = &to
+	}
+}
+
+// WithCancellationDeadlineWithin keeps auto-renewing subscriptions whose
+// cancellation deadline falls on one of the next days, today included.
+func WithCancellationDeadlineWithin(days int) ListOption {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return WithCancellationDeadlineBetween(today, today.AddDate(0, 0, days+1))
+}
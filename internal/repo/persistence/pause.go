@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+)
+
+func (r *SubscriptionRepo) StorePause(ctx context.Context, pause *entity.Pause) error {
+	const op = "subscriptionRepo.StorePause"
+	sql, args, err := r.Builder.
+		Insert("subscription_pauses").
+		Columns("subscription_id", "start_date").
+		Values(pause.SubscriptionID, pause.StartDate).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&pause.Id)
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return nil
+}
+
+// ClosePause ends the open pause of a subscription, if there is one.
+func (r *SubscriptionRepo) ClosePause(ctx context.Context, subscriptionID int64, end time.Time) error {
+	const op = "subscriptionRepo.ClosePause"
+
+	sql, args, err := r.Builder.
+		Update("subscription_pauses").
+		Set("end_date", end).
+		Where(squirrel.Eq{"subscription_id": subscriptionID, "end_date": nil}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListPauses returns the pauses of the given subscriptions ordered by start date.
+func (r *SubscriptionRepo) ListPauses(ctx context.Context, subscriptionIDs ...int64) ([]entity.Pause, error) {
+	const op = "subscriptionRepo.ListPauses"
+
+	if len(subscriptionIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, err := r.Builder.
+		Select("id", "subscription_id", "start_date", "COALESCE(end_date, '0001-01-01'::timestamptz)").
+		From("subscription_pauses").
+		Where(squirrel.Eq{"subscription_id": subscriptionIDs}).
+		OrderBy("start_date").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var pauses []entity.Pause
+	for rows.Next() {
+		var pause entity.Pause
+		err := rows.Scan(&pause.Id, &pause.SubscriptionID, &pause.StartDate, &pause.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		pauses = append(pauses, pause)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return pauses, nil
+}
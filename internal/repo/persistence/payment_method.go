@@ -0,0 +1,157 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var paymentMethodColumns = []string{
+	"id", "user_id", "type", "label", "COALESCE(last_four, '')", "created_at",
+}
+
+// scanPaymentMethod reads a row selected with paymentMethodColumns.
+func scanPaymentMethod(row pgx.Row) (entity.PaymentMethod, error) {
+	var method entity.PaymentMethod
+	err := row.Scan(&method.Id, &method.UserID, &method.Type, &method.Label, &method.LastFour, &method.CreatedAt)
+	return method, err
+}
+
+func (r *SubscriptionRepo) StorePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error {
+	const op = "subscriptionRepo.StorePaymentMethod"
+	sql, args, err := r.Builder.
+		Insert("payment_methods").
+		Columns("user_id", "type", "label", "last_four").
+		Values(method.UserID, method.Type, method.Label, nullString(method.LastFour)).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&method.Id, &method.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) GetPaymentMethod(ctx context.Context, userID uuid.UUID, id int64) (*entity.PaymentMethod, error) {
+	const op = "subscriptionRepo.GetPaymentMethod"
+	sql, args, err := r.Builder.
+		Select(paymentMethodColumns...).
+		From("payment_methods").
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	method, err := scanPaymentMethod(r.Querier(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrPaymentMethodNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return &method, nil
+}
+
+func (r *SubscriptionRepo) UpdatePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error {
+	const op = "subscriptionRepo.UpdatePaymentMethod"
+
+	sql, args, err := r.Builder.
+		Update("payment_methods").
+		Set("type", method.Type).
+		Set("label", method.Label).
+		Set("last_four", nullString(method.LastFour)).
+		Where(squirrel.Eq{"id": method.Id, "user_id": method.UserID}).
+		Suffix("RETURNING created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&method.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPaymentMethodNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeletePaymentMethod removes a payment method, the subscriptions paid with
+// it are left without one.
+func (r *SubscriptionRepo) DeletePaymentMethod(ctx context.Context, userID uuid.UUID, id int64) error {
+	const op = "subscriptionRepo.DeletePaymentMethod"
+
+	sql, args, err := r.Builder.
+		Delete("payment_methods").
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrPaymentMethodNotFound)
+	}
+
+	return nil
+}
+
+// ListPaymentMethods returns the payment methods of a user in the order they were added.
+func (r *SubscriptionRepo) ListPaymentMethods(ctx context.Context, userID uuid.UUID) ([]entity.PaymentMethod, error) {
+	const op = "subscriptionRepo.ListPaymentMethods"
+
+	sql, args, err := r.Builder.
+		Select(paymentMethodColumns...).
+		From("payment_methods").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var methods []entity.PaymentMethod
+	for rows.Next() {
+		method, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		methods = append(methods, method)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return methods, nil
+}
@@ -0,0 +1,234 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// planServiceConstraint is the foreign key tying plans to the services catalog.
+const planServiceConstraint = "service_plans_service_id_fkey"
+
+var planColumns = []string{
+	"id", "service_id", "name", "price", "currency", "billing_period", "billing_period_days", "created_at",
+}
+
+// scanPlan reads a row selected with planColumns, followed by dest.
+func scanPlan(row pgx.Row, dest ...any) (*entity.Plan, error) {
+	plan := &entity.Plan{}
+	err := row.Scan(append([]any{
+		&plan.Id, &plan.ServiceID, &plan.Name, &plan.Price, &plan.Currency, &plan.BillingPeriod, &plan.BillingPeriodDays, &plan.CreatedAt,
+	}, dest...)...)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (r *SubscriptionRepo) StorePlan(ctx context.Context, plan *entity.Plan) error {
+	const op = "subscriptionRepo.StorePlan"
+	sql, args, err := r.Builder.
+		Insert("service_plans").
+		Columns("service_id", "name", "price", "currency", "billing_period", "billing_period_days").
+		Values(plan.ServiceID, plan.Name, plan.Price, plan.Currency, plan.BillingPeriod, plan.BillingPeriodDays).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&plan.Id, &plan.CreatedAt)
+	if isForeignKeyViolation(err, planServiceConstraint) {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPlanExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) GetPlan(ctx context.Context, serviceID, id int64) (*entity.Plan, error) {
+	const op = "subscriptionRepo.GetPlan"
+	sql, args, err := r.Builder.
+		Select(planColumns...).
+		From("service_plans").
+		Where(squirrel.Eq{"id": id, "service_id": serviceID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	plan, err := scanPlan(r.Querier(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrPlanNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return plan, nil
+}
+
+// UpdatePlan changes a plan, subscriptions already on it keep their price.
+func (r *SubscriptionRepo) UpdatePlan(ctx context.Context, plan *entity.Plan) error {
+	const op = "subscriptionRepo.UpdatePlan"
+
+	sql, args, err := r.Builder.
+		Update("service_plans").
+		Set("name", plan.Name).
+		Set("price", plan.Price).
+		Set("currency", plan.Currency).
+		Set("billing_period", plan.BillingPeriod).
+		Set("billing_period_days", plan.BillingPeriodDays).
+		Where(squirrel.Eq{"id": plan.Id, "service_id": plan.ServiceID}).
+		Suffix("RETURNING created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&plan.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPlanNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPlanExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeletePlan removes a plan, the subscriptions on it keep their price.
+func (r *SubscriptionRepo) DeletePlan(ctx context.Context, serviceID, id int64) error {
+	const op = "subscriptionRepo.DeletePlan"
+
+	sql, args, err := r.Builder.
+		Delete("service_plans").
+		Where(squirrel.Eq{"id": id, "service_id": serviceID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrPlanNotFound)
+	}
+
+	return nil
+}
+
+// ListPlans returns the plans of a service ordered by price.
+func (r *SubscriptionRepo) ListPlans(ctx context.Context, serviceID int64) ([]entity.Plan, error) {
+	const op = "subscriptionRepo.ListPlans"
+
+	sql, args, err := r.Builder.
+		Select(planColumns...).
+		From("service_plans").
+		Where(squirrel.Eq{"service_id": serviceID}).
+		OrderBy("price", "name").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var plans []entity.Plan
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		plans = append(plans, *plan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return plans, nil
+}
+
+// PlanUsage counts the subscriptions active on the given date on every
+// plan, or on the plans of one service, and the users holding them.
+func (r *SubscriptionRepo) PlanUsage(ctx context.Context, serviceID *int64, on time.Time) ([]entity.PlanUsage, error) {
+	const op = "subscriptionRepo.PlanUsage"
+
+	columns := make([]string, len(planColumns))
+	for i, column := range planColumns {
+		columns[i] = "p." + column
+	}
+
+	builder := r.Builder.
+		Select(columns...).
+		Column("s.name").
+		Column("COUNT(DISTINCT sub.user_id)").
+		Column("COUNT(sub.id)").
+		From("service_plans p").
+		Join("services s ON s.id = p.service_id").
+		LeftJoin(
+			"subscriptions sub ON sub.plan_id = p.id AND sub.start_date <= ? AND "+
+				"(sub.end_date > ? OR sub.end_date IS NULL OR sub.end_date = '0001-01-01'::timestamp)",
+			on, on,
+		).
+		GroupBy("p.id", "s.name").
+		OrderBy("s.name", "p.price", "p.name")
+
+	if serviceID != nil {
+		builder = builder.Where(squirrel.Eq{"p.service_id": *serviceID})
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var usage []entity.PlanUsage
+	for rows.Next() {
+		var item entity.PlanUsage
+		plan, err := scanPlan(rows, &item.ServiceName, &item.Users, &item.Subscriptions)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		item.Plan = *plan
+		usage = append(usage, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return usage, nil
+}
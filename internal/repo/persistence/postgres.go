@@ -2,12 +2,83 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
 	"github.com/M1r0-dev/Subscription-Aggregator/pkg/postgres"
 	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// subscriptionColumns names a subscription after its catalog entry, so that a
+// renamed or merged service shows up under its current name.
+var subscriptionColumns = []string{
+	"id", "COALESCE(service_id, 0)", "COALESCE((SELECT name FROM services WHERE services.id = subscriptions.service_id), service_name)", "COALESCE(plan_id, 0)", "price", "currency", "billing_period", "billing_period_days", "status", "user_id", "start_date", "end_date",
+	"COALESCE(trial_start_date, '0001-01-01'::timestamptz)", "COALESCE(trial_end_date, '0001-01-01'::timestamptz)",
+	"COALESCE(tax_region, '')", "tax_inclusive", "COALESCE(payment_method_id, 0)",
+	"auto_renew", "cancellation_notice_days", "notes", "attributes", "version",
+}
+
+// subscriptionCurrencyColumn selects the currency of the subscription a row
+// of a subscription_* table belongs to, which its amounts are in.
+const subscriptionCurrencyColumn = "(SELECT currency FROM subscriptions WHERE subscriptions.id = subscription_id)"
+
+// scanSubscription reads a row selected with subscriptionColumns.
+func scanSubscription(row pgx.Row) (*entity.Subscription, error) {
+	sub := &entity.Subscription{}
+	err := row.Scan(
+		&sub.Id, &sub.ServiceID, &sub.ServiceName, &sub.PlanID, &sub.Price, &sub.Currency, &sub.BillingPeriod, &sub.BillingPeriodDays, &sub.Status, &sub.UserID, &sub.StartDate, &sub.EndDate,
+		&sub.TrialStartDate, &sub.TrialEndDate,
+		&sub.TaxRegion, &sub.TaxInclusive, &sub.PaymentMethodID,
+		&sub.AutoRenew, &sub.CancellationNoticeDays, &sub.Notes, &sub.Attributes, &sub.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// attributes stores missing custom attributes as an empty object rather
+// than a JSON null.
+func attributes(attrs map[string]string) map[string]string {
+	if attrs == nil {
+		return map[string]string{}
+	}
+	return attrs
+}
+
+// nullString stores an empty string as NULL.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullID stores a zero ID as NULL.
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+const (
+	// taxRegionConstraint is the foreign key tying subscriptions to the tax_rates table.
+	taxRegionConstraint = "subscriptions_tax_region_fkey"
+	// planConstraint is the foreign key tying subscriptions to the service_plans table.
+	planConstraint = "subscriptions_plan_id_fkey"
 )
 
 type SubscriptionRepo struct {
@@ -24,16 +95,33 @@ func (r *SubscriptionRepo) Store(ctx context.Context, sub *entity.Subscription)
 	const op = "subscriptionRepo.Store"
 	sql, args, err := r.Builder.
 		Insert("subscriptions").
-		Columns("service_name", "price", "user_id", "start_date", "end_date").
-		Values(sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate).
-		Suffix("RETURNING id").
+		Columns(
+			"service_id", "service_name", "plan_id", "price", "currency", "billing_period", "billing_period_days", "status", "user_id", "start_date", "end_date",
+			"trial_start_date", "trial_end_date", "tax_region", "tax_inclusive", "payment_method_id",
+			"auto_renew", "cancellation_notice_days", "notes", "attributes",
+		).
+		Values(
+			sub.ServiceID, sub.ServiceName, nullID(sub.PlanID), sub.Price, sub.Currency, sub.BillingPeriod, sub.BillingPeriodDays, sub.Status, sub.UserID, sub.StartDate, sub.EndDate,
+			nullTime(sub.TrialStartDate), nullTime(sub.TrialEndDate), nullString(sub.TaxRegion), sub.TaxInclusive, nullID(sub.PaymentMethodID),
+			sub.AutoRenew, sub.CancellationNoticeDays, sub.Notes, attributes(sub.Attributes),
+		).
+		Suffix("RETURNING id, version").
 		ToSql()
 
 	if err != nil {
 		return fmt.Errorf("%s: build query: %w", op, err)
 	}
 
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&sub.Id)
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&sub.Id, &sub.Version)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrSubscriptionExists)
+	}
+	if isForeignKeyViolation(err, taxRegionConstraint) {
+		return fmt.Errorf("%s: %w", op, entity.ErrTaxRegionNotFound)
+	}
+	if isForeignKeyViolation(err, planConstraint) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPlanNotFound)
+	}
 	if err != nil {
 		return fmt.Errorf("%s: execute query %w", op, err)
 	}
@@ -44,7 +132,7 @@ func (r *SubscriptionRepo) Store(ctx context.Context, sub *entity.Subscription)
 func (r *SubscriptionRepo) Get(ctx context.Context, id int) (*entity.Subscription, error) {
 	const op = "subscriptionRepo.Get"
 	sql, args, err := r.Builder.
-		Select("id", "service_name", "price", "user_id", "start_date", "end_date").
+		Select(subscriptionColumns...).
 		From("subscriptions").
 		Where(squirrel.Eq{"id": id}).
 		ToSql()
@@ -52,8 +140,10 @@ func (r *SubscriptionRepo) Get(ctx context.Context, id int) (*entity.Subscriptio
 	if err != nil {
 		return nil, fmt.Errorf("%s: build query: %w", op, err)
 	}
-	sub := &entity.Subscription{}
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&sub.Id, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate)
+	sub, err := scanSubscription(r.Querier(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrSubscriptionNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: execute query %w", op, err)
 	}
@@ -66,56 +156,133 @@ func (r *SubscriptionRepo) Update(ctx context.Context, sub *entity.Subscription)
 
 	sql, args, err := r.Builder.
 		Update("subscriptions").
+		Set("service_id", sub.ServiceID).
 		Set("service_name", sub.ServiceName).
+		Set("plan_id", nullID(sub.PlanID)).
 		Set("price", sub.Price).
+		Set("currency", sub.Currency).
+		Set("billing_period", sub.BillingPeriod).
+		Set("billing_period_days", sub.BillingPeriodDays).
+		Set("status", sub.Status).
 		Set("user_id", sub.UserID).
 		Set("start_date", sub.StartDate).
 		Set("end_date", sub.EndDate).
-		Where(squirrel.Eq{"id": sub.Id}).
+		Set("trial_start_date", nullTime(sub.TrialStartDate)).
+		Set("trial_end_date", nullTime(sub.TrialEndDate)).
+		Set("tax_region", nullString(sub.TaxRegion)).
+		Set("tax_inclusive", sub.TaxInclusive).
+		Set("payment_method_id", nullID(sub.PaymentMethodID)).
+		Set("auto_renew", sub.AutoRenew).
+		Set("cancellation_notice_days", sub.CancellationNoticeDays).
+		Set("notes", sub.Notes).
+		Set("attributes", attributes(sub.Attributes)).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": sub.Id, "version": sub.Version}).
 		ToSql()
 
 	if err != nil {
 		return fmt.Errorf("%s: build query: %w", op, err)
 	}
 
-	result, err := r.Pool.Exec(ctx, sql, args...)
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if isForeignKeyViolation(err, taxRegionConstraint) {
+		return fmt.Errorf("%s: %w", op, entity.ErrTaxRegionNotFound)
+	}
+	if isForeignKeyViolation(err, planConstraint) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPlanNotFound)
+	}
 	if err != nil {
 		return fmt.Errorf("%s: execute query: %w", op, err)
 	}
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("%s: no rows affected", op)
+		return fmt.Errorf("%s: %w", op, r.missingOrModified(ctx, int(sub.Id)))
 	}
+	sub.Version++
 
 	return nil
 }
 
-func (r *SubscriptionRepo) Delete(ctx context.Context, id int) error {
-	const op = "subscriptionRepo.Delete"
+func (r *SubscriptionRepo) Exists(ctx context.Context, userID uuid.UUID, serviceID int64, startDate time.Time) (bool, error) {
+	const op = "subscriptionRepo.Exists"
 
 	sql, args, err := r.Builder.
-		Delete("subscriptions").
-		Where(squirrel.Eq{"id": id}).
+		Select("1").
+		Prefix("SELECT EXISTS (").
+		From("subscriptions").
+		Where(squirrel.Eq{"user_id": userID, "service_id": serviceID, "start_date": startDate}).
+		Suffix(")").
 		ToSql()
 
+	if err != nil {
+		return false, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	var exists bool
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return exists, nil
+}
+
+// Delete removes a subscription, only at one of the given versions if any.
+func (r *SubscriptionRepo) Delete(ctx context.Context, id int, versions ...int64) error {
+	const op = "subscriptionRepo.Delete"
+
+	builder := r.Builder.
+		Delete("subscriptions").
+		Where(squirrel.Eq{"id": id})
+	if len(versions) > 0 {
+		builder = builder.Where(squirrel.Eq{"version": versions})
+	}
+
+	sql, args, err := builder.ToSql()
+
 	if err != nil {
 		return fmt.Errorf("%s: build query: %w", op, err)
 	}
 
-	result, err := r.Pool.Exec(ctx, sql, args...)
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("%s: execute query: %w", op, err)
 	}
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("%s: subscription not found", op)
+		return fmt.Errorf("%s: %w", op, r.missingOrModified(ctx, id))
 	}
 
 	return nil
 }
 
+// missingOrModified tells why a write conditioned on the version of a
+// subscription touched no row, the subscription is gone or has moved on.
+func (r *SubscriptionRepo) missingOrModified(ctx context.Context, id int) error {
+	sql, args, err := r.Builder.
+		Select("1").
+		From("subscriptions").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	var exists int
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("execute query: %w", err)
+	}
+
+	return entity.ErrVersionMismatch
+}
+
 func (r *SubscriptionRepo) List(ctx context.Context, opts ...ListOption) ([]*entity.Subscription, error) {
 	const op = "subscriptionRepo.List"
 	options := &ListOptions{
@@ -129,30 +296,13 @@ func (r *SubscriptionRepo) List(ctx context.Context, opts ...ListOption) ([]*ent
 	}
 
 	builder := r.Builder.
-		Select("id", "service_name", "price", "user_id", "start_date", "end_date").
+		Select(subscriptionColumns...).
 		From("subscriptions")
 
-	if options.UserID != nil {
-		builder = builder.Where(squirrel.Eq{"user_id": *options.UserID})
-	}
-
-	if options.ServiceName != nil {
-		builder = builder.Where(squirrel.Eq{"service_name": *options.ServiceName})
-	}
-
-	if options.Price != nil {
-		builder = builder.Where(squirrel.Eq{"price": *options.Price})
-	}
+	builder = applyFilters(builder, options)
 
-	if options.StartDateFrom != nil {
-		builder = builder.Where(squirrel.GtOrEq{"start_date": *options.StartDateFrom})
-	}
-
-	if options.StartDateTo != nil {
-		builder = builder.Where(squirrel.LtOrEq{"start_date": *options.StartDateTo})
-	}
-
-	builder = builder.OrderBy(fmt.Sprintf("%s %s", options.SortBy, options.SortOrder))
+	// id breaks the ties, so that the pages do not overlap
+	builder = builder.OrderBy(fmt.Sprintf("%s %s", options.SortBy, options.SortOrder), "id")
 
 	if options.Limit > 0 {
 		builder = builder.Limit(uint64(options.Limit))
@@ -167,7 +317,7 @@ func (r *SubscriptionRepo) List(ctx context.Context, opts ...ListOption) ([]*ent
 		return nil, fmt.Errorf("%s: build query: %w", op, err)
 	}
 
-	rows, err := r.Pool.Query(ctx, sql, args...)
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: execute query: %w", op, err)
 	}
@@ -175,14 +325,11 @@ func (r *SubscriptionRepo) List(ctx context.Context, opts ...ListOption) ([]*ent
 
 	var subscriptions []*entity.Subscription
 	for rows.Next() {
-		var sub entity.Subscription
-		err := rows.Scan(
-			&sub.Id, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate,
-		)
+		sub, err := scanSubscription(rows)
 		if err != nil {
 			return nil, fmt.Errorf("%s: scan row: %w", op, err)
 		}
-		subscriptions = append(subscriptions, &sub)
+		subscriptions = append(subscriptions, sub)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -203,21 +350,7 @@ func (r *SubscriptionRepo) Count(ctx context.Context, opts ...ListOption) (int,
 	builder := r.Builder.Select("COUNT(*)").
 		From("subscriptions")
 
-	if options.UserID != nil {
-		builder = builder.Where(squirrel.Eq{"user_id": *options.UserID})
-	}
-	if options.ServiceName != nil {
-		builder = builder.Where(squirrel.Eq{"service_name": *options.ServiceName})
-	}
-	if options.Price != nil {
-		builder = builder.Where(squirrel.Eq{"price": *options.Price})
-	}
-	if options.StartDateFrom != nil {
-		builder = builder.Where(squirrel.GtOrEq{"start_date": *options.StartDateFrom})
-	}
-	if options.StartDateTo != nil {
-		builder = builder.Where(squirrel.LtOrEq{"start_date": *options.StartDateTo})
-	}
+	builder = applyFilters(builder, options)
 
 	sql, args, err := builder.ToSql()
 	if err != nil {
@@ -225,7 +358,7 @@ func (r *SubscriptionRepo) Count(ctx context.Context, opts ...ListOption) (int,
 	}
 
 	var count int
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&count)
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("%s: execute query: %w", op, err)
 	}
@@ -233,43 +366,83 @@ func (r *SubscriptionRepo) Count(ctx context.Context, opts ...ListOption) (int,
 	return count, nil
 }
 
-func (r *SubscriptionRepo) GetTotalCost(ctx context.Context, userID *string, serviceName *string, startDate, endDate string) (uint64, error) {
-	const op = "subscriptionRepo.GetTotalCost"
+// applyFilters narrows a subscriptions query down to the filters set in options.
+func applyFilters(builder squirrel.SelectBuilder, options *ListOptions) squirrel.SelectBuilder {
+	if options.UserID != nil {
+		// members of a shared subscription see it as well as its owner
+		builder = builder.Where(squirrel.Or{
+			squirrel.Eq{"user_id": *options.UserID},
+			squirrel.Expr("id IN (SELECT subscription_id FROM subscription_shares WHERE user_id = ?)", *options.UserID),
+		})
+	}
 
-	start, err := time.Parse("2006-01-02", startDate)
-	if err != nil {
-		return 0, fmt.Errorf("%s: invalid start date format: %w", op, err)
+	if options.ServiceName != nil {
+		// any alias of the service matches
+		builder = builder.Where(
+			"service_id IN (SELECT service_id FROM service_aliases WHERE alias = ?)",
+			entity.NormalizeServiceAlias(*options.ServiceName),
+		)
 	}
 
-	end, err := time.Parse("2006-01-02", endDate)
-	if err != nil {
-		return 0, fmt.Errorf("%s: invalid end date format: %w", op, err)
+	if options.Price != nil {
+		builder = builder.Where(squirrel.Eq{"price": *options.Price})
 	}
 
-	builder := r.Builder.
-		Select("COALESCE(SUM(price), 0)").
-		From("subscriptions").
-		Where("start_date <= ?", end).                                                            
-		Where("(end_date >= ? OR end_date IS NULL OR end_date = '0001-01-01'::timestamp)", start)
+	if options.StartDateFrom != nil {
+		builder = builder.Where(squirrel.GtOrEq{"start_date": *options.StartDateFrom})
+	}
 
-	if userID != nil && *userID != "" {
-		builder = builder.Where(squirrel.Eq{"user_id": *userID})
+	if options.StartDateTo != nil {
+		builder = builder.Where(squirrel.LtOrEq{"start_date": *options.StartDateTo})
 	}
 
-	if serviceName != nil && *serviceName != "" {
-		builder = builder.Where(squirrel.Eq{"service_name": *serviceName})
+	if options.ActiveFrom != nil && options.ActiveTo != nil {
+		builder = builder.
+			Where("start_date < ?", *options.ActiveTo).
+			Where("(end_date > ? OR end_date IS NULL OR end_date = '0001-01-01'::timestamp)", *options.ActiveFrom)
 	}
 
-	sql, args, err := builder.ToSql()
-	if err != nil {
-		return 0, fmt.Errorf("%s: build query: %w", op, err)
+	if options.TrialEndFrom != nil {
+		builder = builder.Where(squirrel.GtOrEq{"trial_end_date": *options.TrialEndFrom})
 	}
 
-	var total uint64
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&total)
-	if err != nil {
-		return 0, fmt.Errorf("%s: execute query: %w", op, err)
+	if options.TrialEndTo != nil {
+		builder = builder.Where(squirrel.LtOrEq{"trial_end_date": *options.TrialEndTo})
+	}
+
+	if len(options.Attributes) > 0 {
+		// a single containment test holds every filter, the GIN index serves it
+		builder = builder.Where("attributes @> ?", attributes(options.Attributes))
+	}
+
+	if options.CancellationDeadlineFrom != nil && options.CancellationDeadlineTo != nil {
+		// the deadline is the notice period before a renewal a pause does not skip
+		builder = builder.Where(`auto_renew AND EXISTS (
+			SELECT 1
+			FROM subscription_renewals(
+				GREATEST(start_date, COALESCE(trial_end_date, start_date)), start_date, end_date,
+				billing_period, billing_period_days,
+				?::timestamptz + make_interval(days => cancellation_notice_days),
+				?::timestamptz + make_interval(days => cancellation_notice_days)
+			) AS renewal
+			WHERE NOT EXISTS (
+				SELECT 1 FROM subscription_pauses p
+				WHERE p.subscription_id = subscriptions.id
+					AND renewal >= p.start_date AND (p.end_date IS NULL OR renewal < p.end_date)
+			)
+		)`, *options.CancellationDeadlineFrom, *options.CancellationDeadlineTo)
+	}
+
+	if options.PaymentMethodID != nil {
+		builder = builder.Where(squirrel.Eq{"payment_method_id": *options.PaymentMethodID})
+	}
+
+	if len(options.Tags) > 0 {
+		builder = builder.Where(
+			"id IN (SELECT st.subscription_id FROM subscription_tags st JOIN tags t ON t.id = st.tag_id WHERE t.name = ANY(?))",
+			options.Tags,
+		)
 	}
 
-	return total, nil
+	return builder
 }
@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+)
+
+func (r *SubscriptionRepo) StorePriceChange(ctx context.Context, change *entity.PriceChange) error {
+	const op = "subscriptionRepo.StorePriceChange"
+	sql, args, err := r.Builder.
+		Insert("subscription_price_changes").
+		Columns("subscription_id", "price", "effective_date").
+		Values(change.SubscriptionID, change.Price, change.EffectiveDate).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&change.Id, &change.CreatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPriceChangeExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return nil
+}
+
+// ListPriceChanges returns the price changes of the given subscriptions
+// ordered by effective date.
+func (r *SubscriptionRepo) ListPriceChanges(ctx context.Context, subscriptionIDs ...int64) ([]entity.PriceChange, error) {
+	const op = "subscriptionRepo.ListPriceChanges"
+
+	if len(subscriptionIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, err := r.Builder.
+		Select("id", "subscription_id", "price", subscriptionCurrencyColumn, "effective_date", "created_at").
+		From("subscription_price_changes").
+		Where(squirrel.Eq{"subscription_id": subscriptionIDs}).
+		OrderBy("effective_date").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var changes []entity.PriceChange
+	for rows.Next() {
+		var change entity.PriceChange
+		err := rows.Scan(&change.Id, &change.SubscriptionID, &change.Price, &change.Currency, &change.EffectiveDate, &change.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return changes, nil
+}
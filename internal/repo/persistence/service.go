@@ -0,0 +1,387 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// serviceConstraint is the foreign key tying subscriptions to the services catalog.
+const serviceConstraint = "subscriptions_service_id_fkey"
+
+// StoreService adds a service to the catalog along with its aliases. Call it
+// within a transaction so that a taken alias does not leave the service behind.
+func (r *SubscriptionRepo) StoreService(ctx context.Context, service *entity.Service) error {
+	const op = "subscriptionRepo.StoreService"
+	sql, args, err := r.Builder.
+		Insert("services").
+		Columns("name").
+		Values(service.Name).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&service.Id, &service.CreatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	if err := r.insertServiceAliases(ctx, op, service.Id, service.Aliases); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) GetService(ctx context.Context, id int64) (*entity.Service, error) {
+	const op = "subscriptionRepo.GetService"
+
+	services, err := r.selectServices(ctx, op, r.Builder.
+		Select("id", "name", "created_at").
+		From("services").
+		Where(squirrel.Eq{"id": id}))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrServiceNotFound)
+	}
+
+	return &services[0], nil
+}
+
+// FindService returns the service one of whose aliases is the normalized name.
+func (r *SubscriptionRepo) FindService(ctx context.Context, name string) (*entity.Service, error) {
+	const op = "subscriptionRepo.FindService"
+
+	services, err := r.selectServices(ctx, op, r.Builder.
+		Select("s.id", "s.name", "s.created_at").
+		From("services s").
+		Join("service_aliases a ON a.service_id = s.id").
+		Where(squirrel.Eq{"a.alias": entity.NormalizeServiceAlias(name)}))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrServiceNotFound)
+	}
+
+	return &services[0], nil
+}
+
+// UpdateService renames a service and replaces its aliases. Call it within a
+// transaction to keep the swap atomic.
+func (r *SubscriptionRepo) UpdateService(ctx context.Context, service *entity.Service) error {
+	const op = "subscriptionRepo.UpdateService"
+
+	sql, args, err := r.Builder.
+		Update("services").
+		Set("name", service.Name).
+		Where(squirrel.Eq{"id": service.Id}).
+		Suffix("RETURNING created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&service.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	sql, args, err = r.Builder.
+		Delete("service_aliases").
+		Where(squirrel.Eq{"service_id": service.Id}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return r.insertServiceAliases(ctx, op, service.Id, service.Aliases)
+}
+
+// DeleteService removes a service no subscription refers to.
+func (r *SubscriptionRepo) DeleteService(ctx context.Context, id int64) error {
+	const op = "subscriptionRepo.DeleteService"
+
+	sql, args, err := r.Builder.
+		Delete("services").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if isForeignKeyViolation(err, serviceConstraint) {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceInUse)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceNotFound)
+	}
+
+	return nil
+}
+
+// ListServices returns the whole catalog ordered by name.
+func (r *SubscriptionRepo) ListServices(ctx context.Context) ([]entity.Service, error) {
+	const op = "subscriptionRepo.ListServices"
+
+	return r.selectServices(ctx, op, r.Builder.
+		Select("id", "name", "created_at").
+		From("services").
+		OrderBy("name"))
+}
+
+// MergeServices folds the duplicates into the service: their subscriptions,
+// plans and aliases move over and the duplicates are removed. A plan of a
+// duplicate named like one of the service is dropped for the latter. Call it
+// within a transaction so that a missing duplicate undoes the merge.
+func (r *SubscriptionRepo) MergeServices(ctx context.Context, id int64, duplicateIDs []int64) error {
+	const op = "subscriptionRepo.MergeServices"
+
+	sql, args, err := r.Builder.
+		Update("subscriptions").
+		Set("service_id", id).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"service_id": duplicateIDs}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if isUniqueViolation(err) {
+		// a user has subscriptions to two of them starting on the same date
+		return fmt.Errorf("%s: %w", op, entity.ErrSubscriptionExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	sql, args, err = r.Builder.
+		Update("subscriptions").
+		Set("plan_id", squirrel.Expr("kept.id")).
+		From("service_plans dup JOIN service_plans kept ON kept.name = dup.name").
+		Where("subscriptions.plan_id = dup.id").
+		Where(squirrel.Eq{"dup.service_id": duplicateIDs, "kept.service_id": id}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	sql, args, err = r.Builder.
+		Update("service_plans").
+		Set("service_id", id).
+		Where(squirrel.Eq{"service_id": duplicateIDs}).
+		Where("name NOT IN (SELECT name FROM service_plans WHERE service_id = ?)", id).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if isUniqueViolation(err) {
+		// two duplicates have a plan of the same name
+		return fmt.Errorf("%s: %w", op, entity.ErrPlanExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	// the names of the duplicates become aliases of the service
+	sql, args, err = r.Builder.
+		Insert("service_aliases").
+		Columns("alias", "service_id").
+		Select(r.Builder.
+			Select("LOWER(name)").
+			Column("?::bigint", id).
+			From("services").
+			Where(squirrel.Eq{"id": duplicateIDs})).
+		Suffix("ON CONFLICT (alias) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	sql, args, err = r.Builder.
+		Update("service_aliases").
+		Set("service_id", id).
+		Where(squirrel.Eq{"service_id": duplicateIDs}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	sql, args, err = r.Builder.
+		Delete("services").
+		Where(squirrel.Eq{"id": duplicateIDs}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if result.RowsAffected() != int64(len(duplicateIDs)) {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceNotFound)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) insertServiceAliases(ctx context.Context, op string, serviceID int64, aliases []string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	builder := r.Builder.
+		Insert("service_aliases").
+		Columns("alias", "service_id")
+	for _, alias := range aliases {
+		builder = builder.Values(alias, serviceID)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrServiceExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+// selectServices runs a query selecting id, name and created_at of services
+// and loads their aliases.
+func (r *SubscriptionRepo) selectServices(ctx context.Context, op string, builder squirrel.SelectBuilder) ([]entity.Service, error) {
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var services []entity.Service
+	for rows.Next() {
+		var service entity.Service
+		if err := rows.Scan(&service.Id, &service.Name, &service.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		services = append(services, service)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, len(services))
+	for i, service := range services {
+		ids[i] = service.Id
+	}
+
+	sql, args, err = r.Builder.
+		Select("service_id", "alias").
+		From("service_aliases").
+		Where(squirrel.Eq{"service_id": ids}).
+		OrderBy("alias").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	aliasRows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer aliasRows.Close()
+
+	aliases := map[int64][]string{}
+	for aliasRows.Next() {
+		var serviceID int64
+		var alias string
+		if err := aliasRows.Scan(&serviceID, &alias); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		aliases[serviceID] = append(aliases[serviceID], alias)
+	}
+
+	if err = aliasRows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	for i := range services {
+		services[i].Aliases = aliases[services[i].Id]
+	}
+
+	return services, nil
+}
@@ -0,0 +1,94 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+)
+
+// ReplaceShares swaps the members of a subscription for the given shares.
+// Call it within a transaction to keep the swap atomic.
+func (r *SubscriptionRepo) ReplaceShares(ctx context.Context, subscriptionID int64, shares []entity.Share) error {
+	const op = "subscriptionRepo.ReplaceShares"
+
+	sql, args, err := r.Builder.
+		Delete("subscription_shares").
+		Where(squirrel.Eq{"subscription_id": subscriptionID}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if len(shares) == 0 {
+		return nil
+	}
+
+	builder := r.Builder.
+		Insert("subscription_shares").
+		Columns("subscription_id", "user_id", "weight", "fixed_amount")
+
+	for _, share := range shares {
+		builder = builder.Values(subscriptionID, share.UserID, share.Weight, share.FixedAmount)
+	}
+
+	sql, args, err = builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) ListShares(ctx context.Context, subscriptionIDs ...int64) ([]entity.Share, error) {
+	const op = "subscriptionRepo.ListShares"
+
+	if len(subscriptionIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, err := r.Builder.
+		Select("subscription_id", "user_id", "weight", "fixed_amount", subscriptionCurrencyColumn).
+		From("subscription_shares").
+		Where(squirrel.Eq{"subscription_id": subscriptionIDs}).
+		OrderBy("subscription_id", "user_id").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var shares []entity.Share
+	for rows.Next() {
+		var share entity.Share
+		err := rows.Scan(&share.SubscriptionID, &share.UserID, &share.Weight, &share.FixedAmount, &share.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		shares = append(shares, share)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return shares, nil
+}
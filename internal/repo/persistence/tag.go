@@ -0,0 +1,266 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *SubscriptionRepo) StoreTag(ctx context.Context, tag *entity.Tag) error {
+	const op = "subscriptionRepo.StoreTag"
+	sql, args, err := r.Builder.
+		Insert("tags").
+		Columns("name").
+		Values(tag.Name).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&tag.Id, &tag.CreatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrTagExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepo) GetTag(ctx context.Context, id int64) (*entity.Tag, error) {
+	const op = "subscriptionRepo.GetTag"
+	sql, args, err := r.Builder.
+		Select("id", "name", "created_at").
+		From("tags").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	tag := &entity.Tag{}
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&tag.Id, &tag.Name, &tag.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrTagNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query %w", op, err)
+	}
+
+	return tag, nil
+}
+
+// UpdateTag renames a tag, the subscriptions it labels keep it.
+func (r *SubscriptionRepo) UpdateTag(ctx context.Context, tag *entity.Tag) error {
+	const op = "subscriptionRepo.UpdateTag"
+
+	sql, args, err := r.Builder.
+		Update("tags").
+		Set("name", tag.Name).
+		Where(squirrel.Eq{"id": tag.Id}).
+		Suffix("RETURNING created_at").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&tag.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, entity.ErrTagNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%s: %w", op, entity.ErrTagExists)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteTag removes a tag along with its assignments to subscriptions.
+func (r *SubscriptionRepo) DeleteTag(ctx context.Context, id int64) error {
+	const op = "subscriptionRepo.DeleteTag"
+
+	sql, args, err := r.Builder.
+		Delete("tags").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	result, err := r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrTagNotFound)
+	}
+
+	return nil
+}
+
+// ListTags returns every tag ordered by name.
+func (r *SubscriptionRepo) ListTags(ctx context.Context) ([]entity.Tag, error) {
+	const op = "subscriptionRepo.ListTags"
+
+	return r.selectTags(ctx, op, r.Builder.
+		Select("id", "name", "created_at").
+		From("tags").
+		OrderBy("name"))
+}
+
+// ReplaceSubscriptionTags labels a subscription with the named tags only,
+// creating the tags that do not exist yet. Call it within a transaction to
+// keep the swap atomic.
+func (r *SubscriptionRepo) ReplaceSubscriptionTags(ctx context.Context, subscriptionID int64, names []string) ([]entity.Tag, error) {
+	const op = "subscriptionRepo.ReplaceSubscriptionTags"
+
+	sql, args, err := r.Builder.
+		Delete("subscription_tags").
+		Where(squirrel.Eq{"subscription_id": subscriptionID}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	builder := r.Builder.
+		Insert("tags").
+		Columns("name").
+		Suffix("ON CONFLICT (name) DO NOTHING")
+	for _, name := range names {
+		builder = builder.Values(name)
+	}
+
+	sql, args, err = builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	tags, err := r.selectTags(ctx, op, r.Builder.
+		Select("id", "name", "created_at").
+		From("tags").
+		Where(squirrel.Eq{"name": names}).
+		OrderBy("name"))
+	if err != nil {
+		return nil, err
+	}
+
+	assign := r.Builder.
+		Insert("subscription_tags").
+		Columns("subscription_id", "tag_id")
+	for _, tag := range tags {
+		assign = assign.Values(subscriptionID, tag.Id)
+	}
+
+	sql, args, err = assign.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	_, err = r.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	return tags, nil
+}
+
+// ListSubscriptionTags returns the tags of the given subscriptions, keyed
+// by subscription and ordered by name.
+func (r *SubscriptionRepo) ListSubscriptionTags(ctx context.Context, subscriptionIDs ...int64) (map[int64][]entity.Tag, error) {
+	const op = "subscriptionRepo.ListSubscriptionTags"
+
+	if len(subscriptionIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, err := r.Builder.
+		Select("st.subscription_id", "t.id", "t.name", "t.created_at").
+		From("subscription_tags st").
+		Join("tags t ON t.id = st.tag_id").
+		Where(squirrel.Eq{"st.subscription_id": subscriptionIDs}).
+		OrderBy("st.subscription_id", "t.name").
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	tags := map[int64][]entity.Tag{}
+	for rows.Next() {
+		var subscriptionID int64
+		var tag entity.Tag
+		if err := rows.Scan(&subscriptionID, &tag.Id, &tag.Name, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		tags[subscriptionID] = append(tags[subscriptionID], tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return tags, nil
+}
+
+func (r *SubscriptionRepo) selectTags(ctx context.Context, op string, builder squirrel.SelectBuilder) ([]entity.Tag, error) {
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	rows, err := r.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	var tags []entity.Tag
+	for rows.Next() {
+		var tag entity.Tag
+		if err := rows.Scan(&tag.Id, &tag.Name, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return tags, nil
+}
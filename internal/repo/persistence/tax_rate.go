@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// TaxRateRepo serves tax rates from the local tax_rates table.
+type TaxRateRepo struct {
+	*postgres.Postgres
+}
+
+func NewTaxRateRepo(pg *postgres.Postgres) *TaxRateRepo {
+	return &TaxRateRepo{
+		pg,
+	}
+}
+
+func (r *TaxRateRepo) TaxRate(ctx context.Context, region string) (*big.Rat, error) {
+	const op = "taxRateRepo.TaxRate"
+
+	sql, args, err := r.Builder.
+		Select("rate::text").
+		From("tax_rates").
+		Where(squirrel.Eq{"region": region}).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: build query: %w", op, err)
+	}
+
+	var value string
+	err = r.Querier(ctx).QueryRow(ctx, sql, args...).Scan(&value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %s: %w", op, region, entity.ErrTaxRegionNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute query: %w", op, err)
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid rate %q", op, value)
+	}
+
+	return rate, nil
+}
@@ -2,16 +2,118 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo/persistence"
+	"github.com/google/uuid"
 )
 
 type SubscriptionUsecase interface {
 	Store(ctx context.Context, sub *entity.Subscription) error
 	Get(ctx context.Context, id int) (*entity.Subscription, error)
 	Update(cxt context.Context, sub *entity.Subscription) error
-	Delete(cxt context.Context, id int) error
-	List(cxt context.Context, opts ...persistence.ListOption) ([]*entity.Subscription, error)
-	GetTotalCost(ctx context.Context, userID *string, serviceName *string, startDate, endDate string) (uint64, error)
+	Delete(cxt context.Context, id int, versions ...int64) error
+	List(cxt context.Context, page ListPage, opts ...persistence.ListOption) ([]*entity.Subscription, int, error)
+	GetTotalCost(ctx context.Context, filter CostFilter, groupBy entity.CostGroupBy) (*entity.TotalCost, error)
+	GetCostBreakdown(ctx context.Context, filter CostFilter, groupBy entity.CostGroupBy) ([]entity.MonthlyCost, error)
+	Forecast(ctx context.Context, userID *string, currency string, months int) ([]entity.MonthlyCost, error)
+	AddPriceChange(ctx context.Context, change *entity.PriceChange) error
+	ListPriceChanges(ctx context.Context, subscriptionID int64) ([]entity.PriceChange, error)
+	Pause(ctx context.Context, id int, at time.Time) (*entity.Subscription, error)
+	Resume(ctx context.Context, id int, at time.Time) (*entity.Subscription, error)
+	Cancel(ctx context.Context, id int, at time.Time) (*entity.Subscription, error)
+	ReplaceShares(ctx context.Context, subscriptionID int64, shares []entity.Share) ([]entity.Share, error)
+	ListShares(ctx context.Context, subscriptionID int64) ([]entity.Share, error)
+	AddDiscount(ctx context.Context, discount *entity.Discount) error
+	GetDiscount(ctx context.Context, subscriptionID, id int64) (*entity.Discount, error)
+	UpdateDiscount(ctx context.Context, discount *entity.Discount) error
+	DeleteDiscount(ctx context.Context, subscriptionID, id int64) error
+	ListDiscounts(ctx context.Context, subscriptionID int64) ([]entity.Discount, error)
+	StoreTag(ctx context.Context, tag *entity.Tag) error
+	GetTag(ctx context.Context, id int64) (*entity.Tag, error)
+	UpdateTag(ctx context.Context, tag *entity.Tag) error
+	DeleteTag(ctx context.Context, id int64) error
+	ListTags(ctx context.Context) ([]entity.Tag, error)
+	ReplaceSubscriptionTags(ctx context.Context, subscriptionID int64, names []string) ([]entity.Tag, error)
+	StoreService(ctx context.Context, service *entity.Service) error
+	GetService(ctx context.Context, id int64) (*entity.Service, error)
+	FindService(ctx context.Context, name string) (*entity.Service, error)
+	UpdateService(ctx context.Context, service *entity.Service) error
+	DeleteService(ctx context.Context, id int64) error
+	ListServices(ctx context.Context) ([]entity.Service, error)
+	MergeServices(ctx context.Context, id int64, duplicateIDs []int64) (*entity.Service, error)
+	StorePlan(ctx context.Context, plan *entity.Plan) error
+	GetPlan(ctx context.Context, serviceID, id int64) (*entity.Plan, error)
+	UpdatePlan(ctx context.Context, plan *entity.Plan) error
+	DeletePlan(ctx context.Context, serviceID, id int64) error
+	ListPlans(ctx context.Context, serviceID int64) ([]entity.Plan, error)
+	PlanUsage(ctx context.Context, serviceID *int64, on time.Time) ([]entity.PlanUsage, error)
+	StorePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error
+	GetPaymentMethod(ctx context.Context, userID uuid.UUID, id int64) (*entity.PaymentMethod, error)
+	UpdatePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error
+	DeletePaymentMethod(ctx context.Context, userID uuid.UUID, id int64) ([]*entity.Subscription, error)
+	ListPaymentMethods(ctx context.Context, userID uuid.UUID) ([]entity.PaymentMethod, error)
+	ListPaymentMethodSubscriptions(ctx context.Context, userID uuid.UUID, id int64) ([]*entity.Subscription, error)
+	StoreBudget(ctx context.Context, budget *entity.Budget) error
+	GetBudget(ctx context.Context, userID uuid.UUID, id int64) (*entity.Budget, error)
+	UpdateBudget(ctx context.Context, budget *entity.Budget) error
+	DeleteBudget(ctx context.Context, userID uuid.UUID, id int64) error
+	ListBudgets(ctx context.Context, userID uuid.UUID) ([]entity.Budget, error)
+	BudgetReport(ctx context.Context, userID uuid.UUID, startMonth, endMonth string, basis entity.CostBasis) ([]entity.BudgetUsage, error)
+	BeginIdempotent(ctx context.Context, req *entity.IdempotentRequest) (*entity.IdempotentRequest, error)
+	CompleteIdempotent(ctx context.Context, req *entity.IdempotentRequest) error
+	ReleaseIdempotent(ctx context.Context, req *entity.IdempotentRequest) error
+	Batch(ctx context.Context, ops []BatchOp, atomic bool) ([]BatchResult, error)
+	Import(ctx context.Context, subs []*entity.Subscription, dryRun bool) ([]error, error)
+}
+
+// CostFilter selects the subscriptions and the period a cost is calculated for.
+type CostFilter struct {
+	UserID      *string
+	ServiceName *string
+	Tags        []string // labelled with any of them
+	StartDate   string   // format: YYYY-MM-DD
+	EndDate     string   // format: YYYY-MM-DD, inclusive
+	Basis       entity.CostBasis
+	Currency    string // amounts are converted to it, default: entity.DefaultCurrency
+}
+
+// SortByNextBillingDate orders a list by the computed next billing date
+// instead of a column.
+const SortByNextBillingDate = "next_billing_date"
+
+// ListPage orders the subscriptions of a list and selects a page of them.
+type ListPage struct {
+	SortBy    string // a subscription column or SortByNextBillingDate
+	SortOrder string // asc, desc
+	Page      int
+	PageSize  int
+
+	// keep subscriptions billed next within the range, both ends inclusive
+	NextBillingFrom *time.Time
+	NextBillingTo   *time.Time
+}
+
+type BatchOpKind string
+
+const (
+	BatchCreate BatchOpKind = "create"
+	BatchUpdate BatchOpKind = "update"
+	BatchDelete BatchOpKind = "delete"
+)
+
+// BatchOp is one operation of a batch.
+type BatchOp struct {
+	Kind     BatchOpKind
+	ID       int                  // subscription to update or delete
+	Versions []int64              // the versions it may have, any if none
+	Sub      *entity.Subscription // subscription to create or to replace the one with ID with
+}
+
+// BatchResult is the outcome of a BatchOp, the subscription it created or
+// updated or the error it failed with.
+type BatchResult struct {
+	Sub *entity.Subscription
+	Err error
 }
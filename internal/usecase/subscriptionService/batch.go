@@ -0,0 +1,81 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+)
+
+// errBatchAborted rolls back an atomic batch once an operation failed.
+var errBatchAborted = errors.New("batch aborted")
+
+// Batch runs the operations in order and returns the outcome of each. An
+// atomic batch runs in one transaction and stops at the first operation that
+// fails, undoing the ones before it, whose results are kept all the same.
+// Each operation runs in a transaction of its own besides, so that a service
+// it adds to the catalog is undone along with it.
+func (u *SubscriptionUsecase) Batch(ctx context.Context, ops []usecase.BatchOp, atomic bool) ([]usecase.BatchResult, error) {
+	const op = "subscriptionService.Batch"
+
+	results := make([]usecase.BatchResult, 0, len(ops))
+	run := func(ctx context.Context) error {
+		for _, batchOp := range ops {
+			var sub *entity.Subscription
+			err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+				var err error
+				sub, err = u.runBatchOp(ctx, batchOp)
+				return err
+			})
+			results = append(results, usecase.BatchResult{Sub: sub, Err: err})
+			if err != nil && atomic {
+				return errBatchAborted
+			}
+		}
+		return nil
+	}
+
+	if !atomic {
+		return results, run(ctx)
+	}
+
+	err := u.repo.WithinTx(ctx, run)
+	if err != nil && !errors.Is(err, errBatchAborted) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
+
+func (u *SubscriptionUsecase) runBatchOp(ctx context.Context, op usecase.BatchOp) (*entity.Subscription, error) {
+	switch op.Kind {
+	case usecase.BatchCreate:
+		if err := u.Store(ctx, op.Sub); err != nil {
+			return nil, err
+		}
+		return op.Sub, nil
+	case usecase.BatchUpdate:
+		sub, err := u.repo.Get(ctx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(op.Versions) > 0 && !slices.Contains(op.Versions, sub.Version) {
+			return nil, entity.ErrVersionMismatch
+		}
+		if err := sub.ReplaceWith(op.Sub, time.Now()); err != nil {
+			return nil, err
+		}
+		if err := u.Update(ctx, sub); err != nil {
+			return nil, err
+		}
+		return sub, nil
+	case usecase.BatchDelete:
+		return nil, u.Delete(ctx, op.ID, op.Versions...)
+	default:
+		return nil, fmt.Errorf("unknown batch operation %q", op.Kind)
+	}
+}
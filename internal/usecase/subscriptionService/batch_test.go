@@ -0,0 +1,126 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+	"github.com/google/uuid"
+)
+
+func TestBatch(t *testing.T) {
+	user := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	existing := &entity.Subscription{
+		Id:          1,
+		UserID:      user,
+		ServiceID:   5,
+		ServiceName: "Spotify",
+		Price:       19900,
+		Currency:    "RUB",
+		StartDate:   date(2024, time.January, 1),
+	}
+
+	create := func() usecase.BatchOp {
+		return usecase.BatchOp{Kind: usecase.BatchCreate, Sub: &entity.Subscription{
+			UserID:      user,
+			ServiceName: "Netflix",
+			Price:       29900,
+			Currency:    "RUB",
+			StartDate:   date(2024, time.February, 1),
+		}}
+	}
+	update := func() usecase.BatchOp {
+		replacement := *existing
+		replacement.Price = 24900
+		return usecase.BatchOp{Kind: usecase.BatchUpdate, ID: 1, Versions: []int64{1}, Sub: &replacement}
+	}
+	deleteMissing := usecase.BatchOp{Kind: usecase.BatchDelete, ID: 99}
+
+	tests := []struct {
+		name       string
+		ops        []usecase.BatchOp
+		atomic     bool
+		wantErrs   []error
+		wantWrites int
+		wantPrice  entity.Money
+		wantSubs   int
+	}{
+		{
+			name:      "atomic rolls back the operations before the failing one",
+			ops:       []usecase.BatchOp{create(), update(), deleteMissing},
+			atomic:    true,
+			wantErrs:  []error{nil, nil, entity.ErrSubscriptionNotFound},
+			wantPrice: 19900,
+			wantSubs:  1,
+		},
+		{
+			name:      "atomic stops at the failing one",
+			ops:       []usecase.BatchOp{deleteMissing, create(), update()},
+			atomic:    true,
+			wantErrs:  []error{entity.ErrSubscriptionNotFound},
+			wantPrice: 19900,
+			wantSubs:  1,
+		},
+		{
+			name:       "atomic commits when all succeed",
+			ops:        []usecase.BatchOp{create(), update()},
+			atomic:     true,
+			wantErrs:   []error{nil, nil},
+			wantWrites: 3, // the service, the new subscription and the update
+			wantPrice:  24900,
+			wantSubs:   2,
+		},
+		{
+			name:       "non-atomic keeps the operations that succeed",
+			ops:        []usecase.BatchOp{create(), deleteMissing, update()},
+			wantErrs:   []error{nil, entity.ErrSubscriptionNotFound, nil},
+			wantWrites: 3,
+			wantPrice:  24900,
+			wantSubs:   2,
+		},
+		{
+			name: "a stale version fails",
+			ops: []usecase.BatchOp{
+				{Kind: usecase.BatchUpdate, ID: 1, Versions: []int64{2}, Sub: update().Sub},
+			},
+			wantErrs:  []error{entity.ErrVersionMismatch},
+			wantPrice: 19900,
+			wantSubs:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStubRepo(existing)
+			u := New(r, stubRates{}, stubTaxes{}, nil)
+
+			results, err := u.Batch(context.Background(), tt.ops, tt.atomic)
+			if err != nil {
+				t.Fatalf("Batch() error = %v", err)
+			}
+
+			if len(results) != len(tt.wantErrs) {
+				t.Fatalf("Batch() = %d results, want %d", len(results), len(tt.wantErrs))
+			}
+			for i, result := range results {
+				if !errors.Is(result.Err, tt.wantErrs[i]) {
+					t.Errorf("result %d error = %v, want %v", i, result.Err, tt.wantErrs[i])
+				}
+				if result.Err == nil && result.Sub == nil && tt.ops[i].Kind != usecase.BatchDelete {
+					t.Errorf("result %d has no subscription", i)
+				}
+			}
+
+			if r.writes != tt.wantWrites || len(r.subs) != tt.wantSubs || r.subs[1].Price != tt.wantPrice {
+				t.Errorf("Batch() left %d writes, %d subscriptions, price %d, want %d, %d, %d",
+					r.writes, len(r.subs), r.subs[1].Price, tt.wantWrites, tt.wantSubs, tt.wantPrice)
+			}
+			if _, err := r.FindService(context.Background(), "Netflix"); (err == nil) != (tt.wantSubs == 2) {
+				t.Errorf("Netflix in the catalog = %v, want %v", err == nil, tt.wantSubs == 2)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+	"github.com/google/uuid"
+)
+
+func (u *SubscriptionUsecase) StoreBudget(ctx context.Context, budget *entity.Budget) error {
+	return u.repo.StoreBudget(ctx, budget)
+}
+
+func (u *SubscriptionUsecase) GetBudget(ctx context.Context, userID uuid.UUID, id int64) (*entity.Budget, error) {
+	return u.repo.GetBudget(ctx, userID, id)
+}
+
+func (u *SubscriptionUsecase) UpdateBudget(ctx context.Context, budget *entity.Budget) error {
+	return u.repo.UpdateBudget(ctx, budget)
+}
+
+func (u *SubscriptionUsecase) DeleteBudget(ctx context.Context, userID uuid.UUID, id int64) error {
+	return u.repo.DeleteBudget(ctx, userID, id)
+}
+
+func (u *SubscriptionUsecase) ListBudgets(ctx context.Context, userID uuid.UUID) ([]entity.Budget, error) {
+	return u.repo.ListBudgets(ctx, userID)
+}
+
+// BudgetReport compares the spend of a user in every calendar month from
+// startMonth through endMonth (format: YYYY-MM) with each of their budgets.
+// The spend is worked out the same way as GetTotalCost, in the currency of
+// the budget.
+func (u *SubscriptionUsecase) BudgetReport(
+	ctx context.Context,
+	userID uuid.UUID,
+	startMonth, endMonth string,
+	basis entity.CostBasis,
+) ([]entity.BudgetUsage, error) {
+	const op = "subscriptionService.BudgetReport"
+
+	start, err := time.Parse("2006-01", startMonth)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid start month format: %w", op, err)
+	}
+
+	end, err := time.Parse("2006-01", endMonth)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid end month format: %w", op, err)
+	}
+	// the end month is inclusive, up to its last day
+	end = end.AddDate(0, 1, -1)
+
+	budgets, err := u.repo.ListBudgets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user := userID.String()
+
+	report := make([]entity.BudgetUsage, len(budgets))
+	for i, budget := range budgets {
+		filter := usecase.CostFilter{
+			UserID:    &user,
+			StartDate: start.Format("2006-01-02"),
+			EndDate:   end.Format("2006-01-02"),
+			Basis:     basis,
+			Currency:  budget.Currency,
+		}
+		if budget.Tag != "" {
+			filter.Tags = []string{budget.Tag}
+		}
+		if budget.ServiceName != "" {
+			filter.ServiceName = &budget.ServiceName
+		}
+
+		months, err := u.GetCostBreakdown(ctx, filter, entity.CostGroupByNone)
+		if err != nil {
+			return nil, fmt.Errorf("%s: budget %d: %w", op, budget.Id, err)
+		}
+
+		report[i].Budget = budget
+		for _, month := range months {
+			report[i].Months = append(report[i].Months, entity.BudgetMonth{
+				Month: month.Month,
+				Limit: budget.MonthlyLimit,
+				Spent: month.Total,
+			})
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,72 @@
+package subscriptionservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestBudgetReport(t *testing.T) {
+	user := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	monthly := func(id int64, userID uuid.UUID, service string, price entity.Money, tags ...entity.Tag) *entity.Subscription {
+		return &entity.Subscription{
+			Id:            id,
+			UserID:        userID,
+			ServiceName:   service,
+			Price:         price,
+			Currency:      "RUB",
+			BillingPeriod: entity.BillingMonthly,
+			StartDate:     date(2024, time.January, 1),
+			Tags:          tags,
+		}
+	}
+
+	r := newStubRepo(
+		monthly(1, user, "Netflix", 10000, entity.Tag{Name: "work"}),
+		monthly(2, user, "Spotify", 3000),
+		monthly(3, uuid.MustParse("22222222-2222-2222-2222-222222222222"), "Netflix", 100000),
+	)
+	r.budgets = []entity.Budget{
+		{Id: 1, UserID: user, MonthlyLimit: 15000, Currency: "RUB"},
+		{Id: 2, UserID: user, MonthlyLimit: 5000, Currency: "RUB", Tag: "work"},
+		{Id: 3, UserID: user, MonthlyLimit: 10000, Currency: "RUB", ServiceName: "Netflix"},
+	}
+	u := New(r, stubRates{}, stubTaxes{}, nil)
+
+	report, err := u.BudgetReport(context.Background(), user, "2024-01", "2024-02", entity.CostBasisCash)
+	if err != nil {
+		t.Fatalf("BudgetReport() error = %v", err)
+	}
+
+	tests := []struct {
+		budget        int64
+		wantSpent     entity.Money
+		wantOverspent bool
+	}{
+		{budget: 1, wantSpent: 13000},
+		{budget: 2, wantSpent: 10000, wantOverspent: true},
+		// spending the whole limit is not overspending it
+		{budget: 3, wantSpent: 10000},
+	}
+
+	if len(report) != len(tests) {
+		t.Fatalf("BudgetReport() = %d budgets, want %d", len(report), len(tests))
+	}
+	for i, tt := range tests {
+		usage := report[i]
+		if usage.Budget.Id != tt.budget || len(usage.Months) != 2 {
+			t.Fatalf("BudgetReport()[%d] = budget %d over %d months, want budget %d over 2", i, usage.Budget.Id, len(usage.Months), tt.budget)
+		}
+		for _, month := range usage.Months {
+			if month.Spent != tt.wantSpent || month.Limit != usage.Budget.MonthlyLimit {
+				t.Errorf("budget %d in %s spent %d of %d, want %d of %d", tt.budget, month.Month.Format("2006-01"), month.Spent, month.Limit, tt.wantSpent, usage.Budget.MonthlyLimit)
+			}
+		}
+		if usage.Overspent() != tt.wantOverspent {
+			t.Errorf("budget %d Overspent() = %v, want %v", tt.budget, usage.Overspent(), tt.wantOverspent)
+		}
+	}
+}
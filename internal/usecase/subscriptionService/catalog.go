@@ -0,0 +1,126 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+func (u *SubscriptionUsecase) StoreService(ctx context.Context, service *entity.Service) error {
+	const op = "subscriptionService.StoreService"
+
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return u.repo.StoreService(ctx, service)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (u *SubscriptionUsecase) GetService(ctx context.Context, id int64) (*entity.Service, error) {
+	return u.repo.GetService(ctx, id)
+}
+
+// FindService returns the catalog entry the name is an alias of, without
+// adding one for a name that is not known.
+func (u *SubscriptionUsecase) FindService(ctx context.Context, name string) (*entity.Service, error) {
+	return u.repo.FindService(ctx, name)
+}
+
+// resolveService returns the catalog entry the name is an alias of, adding
+// the name to the catalog as a new service if it is not known yet.
+func (u *SubscriptionUsecase) resolveService(ctx context.Context, name string) (*entity.Service, error) {
+	const op = "subscriptionService.resolveService"
+
+	service, err := u.repo.FindService(ctx, name)
+	if err == nil {
+		return service, nil
+	}
+	if !errors.Is(err, entity.ErrServiceNotFound) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	service = &entity.Service{
+		Name:    entity.NormalizeServiceName(name),
+		Aliases: entity.ServiceAliases(name, nil),
+	}
+	err = u.StoreService(ctx, service)
+	if errors.Is(err, entity.ErrServiceExists) {
+		// added by a concurrent request in the meantime
+		service, err = u.repo.FindService(ctx, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return service, nil
+}
+
+// resolveSubscriptionService puts sub on the catalog entry of its service
+// name when the parser found none, adding it to the catalog.
+func (u *SubscriptionUsecase) resolveSubscriptionService(ctx context.Context, sub *entity.Subscription) error {
+	if sub.ServiceID != 0 {
+		return nil
+	}
+
+	service, err := u.resolveService(ctx, sub.ServiceName)
+	if err != nil {
+		return err
+	}
+	sub.ServiceID = service.Id
+	sub.ServiceName = service.Name
+
+	return nil
+}
+
+// UpdateService renames a service and replaces its aliases.
+func (u *SubscriptionUsecase) UpdateService(ctx context.Context, service *entity.Service) error {
+	const op = "subscriptionService.UpdateService"
+
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		return u.repo.UpdateService(ctx, service)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (u *SubscriptionUsecase) DeleteService(ctx context.Context, id int64) error {
+	return u.repo.DeleteService(ctx, id)
+}
+
+func (u *SubscriptionUsecase) ListServices(ctx context.Context) ([]entity.Service, error) {
+	return u.repo.ListServices(ctx)
+}
+
+// MergeServices folds duplicate catalog entries into the service with the
+// given ID and returns it with the aliases it gained.
+func (u *SubscriptionUsecase) MergeServices(ctx context.Context, id int64, duplicateIDs []int64) (*entity.Service, error) {
+	const op = "subscriptionService.MergeServices"
+
+	var service *entity.Service
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := u.repo.GetService(ctx, id); err != nil {
+			return err
+		}
+
+		if err := u.repo.MergeServices(ctx, id, duplicateIDs); err != nil {
+			return err
+		}
+
+		var err error
+		service, err = u.repo.GetService(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return service, nil
+}
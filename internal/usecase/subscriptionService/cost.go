@@ -0,0 +1,545 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo/persistence"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+	"github.com/google/uuid"
+)
+
+// GetTotalCost sums what the matching subscriptions cost within the filter
+// period split into net and tax, along with what they would have cost
+// without their discounts.
+func (u *SubscriptionUsecase) GetTotalCost(ctx context.Context, filter usecase.CostFilter, groupBy entity.CostGroupBy) (*entity.TotalCost, error) {
+	const op = "subscriptionService.GetTotalCost"
+
+	subscriptions, start, end, err := u.costScope(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	calc, err := u.newCostCalculator(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	grossCalc := calc.withoutDiscounts()
+
+	net, gross, undiscounted := new(big.Rat), new(big.Rat), new(big.Rat)
+	groups := map[string]*big.Rat{}
+	for _, sub := range subscriptions {
+		cost, tax, err := calc.splitCost(ctx, sub, start, end, calc.member)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		net.Add(net, cost)
+		gross.Add(gross, cost.Add(cost, tax))
+
+		if err := calc.addGroupCosts(ctx, groups, groupBy, sub, cost, start, end); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		cost, err = grossCalc.cost(ctx, sub, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		undiscounted.Add(undiscounted, cost)
+	}
+
+	// the tax is what rounding leaves between gross and net so that they add up
+	total := &entity.TotalCost{
+		Net:          entity.RoundMoney(net, calc.currency),
+		Gross:        entity.RoundMoney(gross, calc.currency),
+		Undiscounted: entity.RoundMoney(undiscounted, calc.currency),
+	}
+	total.Tax = total.Gross - total.Net
+	total.Groups = groupCosts(groups, calc.currency)
+
+	return total, nil
+}
+
+func (u *SubscriptionUsecase) GetCostBreakdown(ctx context.Context, filter usecase.CostFilter, groupBy entity.CostGroupBy) ([]entity.MonthlyCost, error) {
+	const op = "subscriptionService.GetCostBreakdown"
+
+	subscriptions, start, end, err := u.costScope(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	calc, err := u.newCostCalculator(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	months, err := monthlyCosts(ctx, calc, subscriptions, start, end, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return months, nil
+}
+
+// monthlyCosts splits the cost of the subscriptions within [start, end) by
+// calendar month and, unless groupBy is none, by group within every month.
+func monthlyCosts(
+	ctx context.Context,
+	calc *costCalculator,
+	subscriptions []*entity.Subscription,
+	start, end time.Time,
+	groupBy entity.CostGroupBy,
+) ([]entity.MonthlyCost, error) {
+	var months []entity.MonthlyCost
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(end); month = month.AddDate(0, 1, 0) {
+		from, to := latest(month, start), earliest(month.AddDate(0, 1, 0), end)
+
+		total := new(big.Rat)
+		groups := map[string]*big.Rat{}
+		for _, sub := range subscriptions {
+			cost, err := calc.cost(ctx, sub, from, to)
+			if err != nil {
+				return nil, err
+			}
+			if cost.Sign() == 0 {
+				continue
+			}
+			total.Add(total, cost)
+
+			if err := calc.addGroupCosts(ctx, groups, groupBy, sub, cost, from, to); err != nil {
+				return nil, err
+			}
+		}
+
+		months = append(months, entity.MonthlyCost{
+			Month:  month,
+			Total:  entity.RoundMoney(total, calc.currency),
+			Groups: groupCosts(groups, calc.currency),
+		})
+	}
+
+	return months, nil
+}
+
+// costScope parses the filter period into a [start, end) window and loads
+// the subscriptions active within it.
+func (u *SubscriptionUsecase) costScope(ctx context.Context, filter usecase.CostFilter) ([]*entity.Subscription, time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", filter.StartDate)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid start date format: %w", err)
+	}
+
+	end, err := time.Parse("2006-01-02", filter.EndDate)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid end date format: %w", err)
+	}
+	// end date is inclusive, charges are counted up to the end of that day
+	end = end.AddDate(0, 0, 1)
+
+	subscriptions, err := u.costSubscriptions(ctx, filter, start, end)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	return subscriptions, start, end, nil
+}
+
+// costSubscriptions loads the subscriptions matching the filter that are
+// active within [start, end), along with their history.
+func (u *SubscriptionUsecase) costSubscriptions(ctx context.Context, filter usecase.CostFilter, start, end time.Time) ([]*entity.Subscription, error) {
+	opts := []persistence.ListOption{
+		persistence.WithActiveBetween(start, end),
+		persistence.WithLimit(0),
+	}
+
+	if filter.UserID != nil && *filter.UserID != "" {
+		id, err := uuid.Parse(*filter.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id: %w", err)
+		}
+		opts = append(opts, persistence.WithUserID(id))
+	}
+
+	if filter.ServiceName != nil && *filter.ServiceName != "" {
+		opts = append(opts, persistence.WithServiceName(*filter.ServiceName))
+	}
+
+	if len(filter.Tags) > 0 {
+		opts = append(opts, persistence.WithTags(filter.Tags...))
+	}
+
+	subscriptions, err := u.repo.List(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.loadHistory(ctx, subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// loadHistory attaches the price changes, pauses, shares and discounts the
+// cost of the subscriptions depends on, along with their tags.
+func (u *SubscriptionUsecase) loadHistory(ctx context.Context, subscriptions []*entity.Subscription) error {
+	ids := make([]int64, len(subscriptions))
+	byID := make(map[int64]*entity.Subscription, len(subscriptions))
+	for i, sub := range subscriptions {
+		ids[i] = sub.Id
+		byID[sub.Id] = sub
+	}
+
+	changes, err := u.repo.ListPriceChanges(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		sub := byID[change.SubscriptionID]
+		sub.PriceChanges = append(sub.PriceChanges, change)
+	}
+
+	pauses, err := u.repo.ListPauses(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	for _, pause := range pauses {
+		sub := byID[pause.SubscriptionID]
+		sub.Pauses = append(sub.Pauses, pause)
+	}
+
+	shares, err := u.repo.ListShares(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	for _, share := range shares {
+		sub := byID[share.SubscriptionID]
+		sub.Shares = append(sub.Shares, share)
+	}
+
+	discounts, err := u.repo.ListDiscounts(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	for _, discount := range discounts {
+		sub := byID[discount.SubscriptionID]
+		sub.Discounts = append(sub.Discounts, discount)
+	}
+
+	tags, err := u.repo.ListSubscriptionTags(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	for id, subTags := range tags {
+		byID[id].Tags = subTags
+	}
+
+	return nil
+}
+
+// addGroupCosts adds cost, what sub costs within [from, to), to the groups
+// it falls in.
+func (c *costCalculator) addGroupCosts(
+	ctx context.Context,
+	groups map[string]*big.Rat,
+	groupBy entity.CostGroupBy,
+	sub *entity.Subscription,
+	cost *big.Rat,
+	from, to time.Time,
+) error {
+	if groupBy == entity.CostGroupByNone || cost.Sign() == 0 {
+		return nil
+	}
+
+	// a shared subscription is split across its members
+	if groupBy == entity.CostGroupByUserID && c.member == nil && len(sub.Shares) > 0 {
+		for _, member := range sub.Members() {
+			memberCost, err := c.costFor(ctx, sub, from, to, &member)
+			if err != nil {
+				return err
+			}
+			addGroupCost(groups, member.String(), memberCost)
+		}
+		return nil
+	}
+
+	for _, key := range groupKeys(sub, groupBy, c.member) {
+		addGroupCost(groups, key, cost)
+	}
+
+	return nil
+}
+
+func addGroupCost(groups map[string]*big.Rat, key string, cost *big.Rat) {
+	if cost.Sign() == 0 {
+		return
+	}
+	if groups[key] == nil {
+		groups[key] = new(big.Rat)
+	}
+	groups[key].Add(groups[key], cost)
+}
+
+// groupKeys returns the groups sub is counted in. When the costs are limited
+// to a single member, that member is the only user group.
+func groupKeys(sub *entity.Subscription, groupBy entity.CostGroupBy, member *uuid.UUID) []string {
+	switch groupBy {
+	case entity.CostGroupByServiceName:
+		return []string{sub.ServiceName}
+	case entity.CostGroupByUserID:
+		if member != nil {
+			return []string{member.String()}
+		}
+		return []string{sub.UserID.String()}
+	case entity.CostGroupByTag:
+		return sub.TagNames()
+	case entity.CostGroupByPaymentMethod:
+		if sub.PaymentMethodID == 0 {
+			return []string{entity.CostGroupNoPaymentMethod}
+		}
+		return []string{strconv.FormatInt(sub.PaymentMethodID, 10)}
+	}
+	return nil
+}
+
+// groupCosts rounds the group totals to the minor unit of currency and
+// orders them by key.
+func groupCosts(groups map[string]*big.Rat, currency string) []entity.GroupCost {
+	var costs []entity.GroupCost
+	for key, cost := range groups {
+		costs = append(costs, entity.GroupCost{Key: key, Total: entity.RoundMoney(cost, currency)})
+	}
+	sort.Slice(costs, func(i, j int) bool { return costs[i].Key < costs[j].Key })
+
+	return costs
+}
+
+// costCalculator prices subscriptions in a single currency on a single basis.
+type costCalculator struct {
+	rates    repo.ExchangeRateProvider
+	taxes    repo.TaxRateProvider
+	basis    entity.CostBasis
+	currency string
+	// member limits the costs to the share paid by a single user, nil counts the full price
+	member *uuid.UUID
+	// discounted takes the discounts off the charges
+	discounted bool
+	// rateCache holds the rates already fetched, keyed by currency and date
+	rateCache map[string]*big.Rat
+	// taxCache holds the tax rates already fetched, keyed by region
+	taxCache map[string]*big.Rat
+}
+
+func (u *SubscriptionUsecase) newCostCalculator(filter usecase.CostFilter) (*costCalculator, error) {
+	currency := filter.Currency
+	if currency == "" {
+		currency = entity.DefaultCurrency
+	}
+
+	calc := &costCalculator{
+		rates:      u.rates,
+		taxes:      u.taxes,
+		basis:      filter.Basis,
+		currency:   currency,
+		discounted: true,
+		rateCache:  map[string]*big.Rat{},
+		taxCache:   map[string]*big.Rat{},
+	}
+
+	if filter.UserID != nil && *filter.UserID != "" {
+		member, err := uuid.Parse(*filter.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id: %w", err)
+		}
+		calc.member = &member
+	}
+
+	return calc, nil
+}
+
+// withoutDiscounts returns a copy of the calculator that ignores discounts.
+func (c *costCalculator) withoutDiscounts() *costCalculator {
+	gross := *c
+	gross.discounted = false
+	return &gross
+}
+
+// cost returns what sub costs within [from, to) tax included, converted at
+// the rate effective on the last day of the window.
+func (c *costCalculator) cost(ctx context.Context, sub *entity.Subscription, from, to time.Time) (*big.Rat, error) {
+	return c.costFor(ctx, sub, from, to, c.member)
+}
+
+// costFor is cost limited to the share paid by member, or the full price if member is nil.
+func (c *costCalculator) costFor(ctx context.Context, sub *entity.Subscription, from, to time.Time, member *uuid.UUID) (*big.Rat, error) {
+	net, tax, err := c.splitCost(ctx, sub, from, to, member)
+	if err != nil {
+		return nil, err
+	}
+
+	return net.Add(net, tax), nil
+}
+
+// splitCost is costFor split into the net amount and the tax.
+func (c *costCalculator) splitCost(ctx context.Context, sub *entity.Subscription, from, to time.Time, member *uuid.UUID) (*big.Rat, *big.Rat, error) {
+	cost := c.subscriptionCost(sub, from, to, member)
+	if cost.Sign() == 0 {
+		return cost, new(big.Rat), nil
+	}
+
+	if sub.Currency != c.currency {
+		rate, err := c.rate(ctx, sub.Currency, to.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, nil, err
+		}
+		cost.Mul(cost, rate)
+	}
+
+	if sub.TaxRegion == "" {
+		return cost, new(big.Rat), nil
+	}
+
+	rate, err := c.taxRate(ctx, sub.TaxRegion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	net, tax := sub.SplitTax(cost, rate)
+	return net, tax, nil
+}
+
+// rate returns what one unit of currency is worth in the calculator currency
+// on the given date, falling back to the inverse of the opposite rate when
+// only that one is known.
+func (c *costCalculator) rate(ctx context.Context, currency string, on time.Time) (*big.Rat, error) {
+	key := currency + on.Format("2006-01-02")
+	if rate, ok := c.rateCache[key]; ok {
+		return rate, nil
+	}
+
+	rate, err := c.rates.Rate(ctx, currency, c.currency, on)
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		inverse, inverseErr := c.rates.Rate(ctx, c.currency, currency, on)
+		switch {
+		case inverseErr == nil:
+			rate, err = inverse.Inv(inverse), nil
+		case !errors.Is(inverseErr, entity.ErrExchangeRateNotFound):
+			err = inverseErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.rateCache[key] = rate
+
+	return rate, nil
+}
+
+func (c *costCalculator) taxRate(ctx context.Context, region string) (*big.Rat, error) {
+	if rate, ok := c.taxCache[region]; ok {
+		return rate, nil
+	}
+
+	rate, err := c.taxes.TaxRate(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	c.taxCache[region] = rate
+
+	return rate, nil
+}
+
+// subscriptionCost returns what sub costs within [from, to) in the major
+// units of its own currency, limited to the share of member unless it is nil.
+func (c *costCalculator) subscriptionCost(sub *entity.Subscription, from, to time.Time, member *uuid.UUID) *big.Rat {
+	cost := new(big.Rat)
+
+	price := func(at time.Time) *big.Rat {
+		amount := sub.PriceOn(at).Rat(sub.Currency)
+		if c.discounted {
+			amount = sub.DiscountedOn(at, amount)
+		}
+		if member == nil {
+			return amount
+		}
+		return sub.ShareOf(*member, amount)
+	}
+
+	if c.basis != entity.CostBasisAccrual {
+		for _, charge := range sub.Charges(from, to) {
+			if sub.PausedAt(charge) {
+				continue
+			}
+			cost.Add(cost, price(charge))
+		}
+		return cost
+	}
+
+	for _, period := range sub.ChargePeriods(from, to) {
+		overlapEnd := earliest(period.End, to)
+		if !sub.EndDate.IsZero() {
+			overlapEnd = earliest(overlapEnd, sub.EndDate)
+		}
+
+		overlapStart := latest(period.Start, from)
+		overlap := daysBetween(overlapStart, overlapEnd) - pausedDays(sub, overlapStart, overlapEnd)
+		if overlap <= 0 {
+			continue
+		}
+
+		share := big.NewRat(overlap, daysBetween(period.Start, period.End))
+		cost.Add(cost, share.Mul(share, price(period.Start)))
+	}
+
+	return cost
+}
+
+// pausedDays counts the days within [from, to) the subscription was paused.
+func pausedDays(sub *entity.Subscription, from, to time.Time) int64 {
+	var days int64
+	for _, pause := range sub.Pauses {
+		end := to
+		if !pause.EndDate.IsZero() {
+			end = earliest(pause.EndDate, to)
+		}
+		if paused := daysBetween(latest(pause.StartDate, from), end); paused > 0 {
+			days += paused
+		}
+	}
+	return days
+}
+
+// daysBetween counts calendar days from a to b, ignoring the time of day.
+func daysBetween(a, b time.Time) int64 {
+	return int64(dayOf(b).Sub(dayOf(a)).Hours() / 24)
+}
+
+func dayOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
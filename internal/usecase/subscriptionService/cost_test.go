@@ -0,0 +1,450 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+	"github.com/google/uuid"
+)
+
+func TestSubscriptionCost(t *testing.T) {
+	// 899 is 31 * 29, the days of January and February 2024
+	monthly := &entity.Subscription{
+		Id:            1,
+		Price:         89900,
+		Currency:      "RUB",
+		BillingPeriod: entity.BillingMonthly,
+		StartDate:     date(2024, time.January, 1),
+	}
+	ended := *monthly
+	ended.EndDate = date(2024, time.January, 20)
+
+	tests := []struct {
+		name     string
+		sub      *entity.Subscription
+		basis    entity.CostBasis
+		from, to time.Time
+		want     *big.Rat
+	}{
+		{
+			name:  "cash counts the charges within the window",
+			sub:   monthly,
+			basis: entity.CostBasisCash,
+			from:  date(2024, time.January, 16),
+			to:    date(2024, time.February, 16),
+			want:  big.NewRat(899, 1),
+		},
+		{
+			name:  "cash leaves out a charge on the end of the window",
+			sub:   monthly,
+			basis: entity.CostBasisCash,
+			from:  date(2024, time.January, 2),
+			to:    date(2024, time.February, 1),
+			want:  new(big.Rat),
+		},
+		{
+			name:  "accrual prorates the periods by day",
+			sub:   monthly,
+			basis: entity.CostBasisAccrual,
+			from:  date(2024, time.January, 16),
+			to:    date(2024, time.February, 16),
+			want:  big.NewRat(16*29+15*31, 1),
+		},
+		{
+			name:  "accrual stops at the end date",
+			sub:   &ended,
+			basis: entity.CostBasisAccrual,
+			from:  date(2024, time.January, 1),
+			to:    date(2024, time.February, 1),
+			want:  big.NewRat(19*29, 1),
+		},
+		{
+			name:  "cash counts the charge before the end date whole",
+			sub:   &ended,
+			basis: entity.CostBasisCash,
+			from:  date(2024, time.January, 1),
+			to:    date(2024, time.February, 1),
+			want:  big.NewRat(899, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := New(newStubRepo(), stubRates{}, stubTaxes{}, nil)
+			calc, err := u.newCostCalculator(usecase.CostFilter{Basis: tt.basis})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := calc.cost(context.Background(), tt.sub, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("cost() error = %v", err)
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("cost() = %s, want %s", got.FloatString(2), tt.want.FloatString(2))
+			}
+		})
+	}
+}
+
+func TestGetTotalCostEndDate(t *testing.T) {
+	sub := &entity.Subscription{
+		Id:            1,
+		Price:         29900,
+		Currency:      "RUB",
+		BillingPeriod: entity.BillingMonthly,
+		StartDate:     date(2024, time.January, 1),
+	}
+
+	tests := []struct {
+		endDate string
+		want    entity.Money
+	}{
+		{endDate: "2024-01-31", want: 29900},
+		// the end date is inclusive, its charge is counted
+		{endDate: "2024-02-01", want: 59800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endDate, func(t *testing.T) {
+			u := New(newStubRepo(sub), stubRates{}, stubTaxes{}, nil)
+			filter := usecase.CostFilter{StartDate: "2024-01-01", EndDate: tt.endDate, Basis: entity.CostBasisCash}
+
+			total, err := u.GetTotalCost(context.Background(), filter, entity.CostGroupByNone)
+			if err != nil {
+				t.Fatalf("GetTotalCost() error = %v", err)
+			}
+			if total.Gross != tt.want {
+				t.Errorf("GetTotalCost() = %s, want %s", total.Gross.Format("RUB"), tt.want.Format("RUB"))
+			}
+		})
+	}
+}
+
+func TestCostConversion(t *testing.T) {
+	sub := &entity.Subscription{
+		Id:            1,
+		Price:         1000,
+		Currency:      "USD",
+		BillingPeriod: entity.BillingMonthly,
+		StartDate:     date(2024, time.January, 1),
+	}
+
+	tests := []struct {
+		name    string
+		rates   stubRates
+		want    *big.Rat
+		wantErr error
+	}{
+		{
+			name:  "at the rate on the last day of the window",
+			rates: stubRates{"USD/RUB 2024-01-01": big.NewRat(80, 1), "USD/RUB 2024-01-31": big.NewRat(90, 1)},
+			want:  big.NewRat(900, 1),
+		},
+		{
+			name:  "inverse of the opposite rate",
+			rates: stubRates{"RUB/USD 2024-01-31": big.NewRat(1, 100)},
+			want:  big.NewRat(1000, 1),
+		},
+		{
+			name:    "only on the charge date",
+			rates:   stubRates{"USD/RUB 2024-01-01": big.NewRat(80, 1)},
+			wantErr: entity.ErrExchangeRateNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := New(newStubRepo(), tt.rates, stubTaxes{}, nil)
+			calc, err := u.newCostCalculator(usecase.CostFilter{Basis: entity.CostBasisCash, Currency: "RUB"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := calc.cost(context.Background(), sub, date(2024, time.January, 1), date(2024, time.February, 1))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("cost() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Cmp(tt.want) != 0 {
+				t.Errorf("cost() = %s, want %s", got.FloatString(2), tt.want.FloatString(2))
+			}
+		})
+	}
+}
+
+func TestGetCostBreakdownPriceChange(t *testing.T) {
+	sub := &entity.Subscription{
+		Id:            1,
+		Price:         10000,
+		Currency:      "RUB",
+		BillingPeriod: entity.BillingMonthly,
+		StartDate:     date(2024, time.January, 1),
+	}
+
+	tests := []struct {
+		basis entity.CostBasis
+		want  []entity.Money // January, February
+	}{
+		// the February charge is at the new price
+		{basis: entity.CostBasisCash, want: []entity.Money{0, 20000}},
+		// 16 days of January at the old price, 15 of February at the new one
+		{basis: entity.CostBasisAccrual, want: []entity.Money{5161, 10345}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.basis), func(t *testing.T) {
+			r := newStubRepo(sub)
+			r.changes = []entity.PriceChange{{SubscriptionID: 1, Price: 20000, Currency: "RUB", EffectiveDate: date(2024, time.February, 1)}}
+			u := New(r, stubRates{}, stubTaxes{}, nil)
+			filter := usecase.CostFilter{StartDate: "2024-01-16", EndDate: "2024-02-15", Basis: tt.basis}
+
+			months, err := u.GetCostBreakdown(context.Background(), filter, entity.CostGroupByNone)
+			if err != nil {
+				t.Fatalf("GetCostBreakdown() error = %v", err)
+			}
+
+			var got []entity.Money
+			for _, month := range months {
+				got = append(got, month.Total)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetCostBreakdown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPausedCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		basis  entity.CostBasis
+		pauses []entity.Pause
+		to     time.Time
+		want   *big.Rat
+	}{
+		{
+			name:   "cash leaves out the charge within a pause",
+			basis:  entity.CostBasisCash,
+			pauses: []entity.Pause{{StartDate: date(2024, time.January, 10), EndDate: date(2024, time.February, 5)}},
+			to:     date(2024, time.March, 1),
+			want:   big.NewRat(899, 1),
+		},
+		{
+			name:   "cash counts the charge on the day a pause ends",
+			basis:  entity.CostBasisCash,
+			pauses: []entity.Pause{{StartDate: date(2024, time.January, 10), EndDate: date(2024, time.February, 1)}},
+			to:     date(2024, time.March, 1),
+			want:   big.NewRat(2*899, 1),
+		},
+		{
+			name:   "cash leaves out the charges of an open pause",
+			basis:  entity.CostBasisCash,
+			pauses: []entity.Pause{{StartDate: date(2024, time.February, 1)}},
+			to:     date(2024, time.April, 1),
+			want:   big.NewRat(899, 1),
+		},
+		{
+			name:   "accrual leaves out the paused days",
+			basis:  entity.CostBasisAccrual,
+			pauses: []entity.Pause{{StartDate: date(2024, time.January, 10), EndDate: date(2024, time.January, 20)}},
+			to:     date(2024, time.February, 1),
+			want:   big.NewRat(21*29, 1),
+		},
+		{
+			name:   "accrual leaves out the days of an open pause",
+			basis:  entity.CostBasisAccrual,
+			pauses: []entity.Pause{{StartDate: date(2024, time.January, 25)}},
+			to:     date(2024, time.February, 1),
+			want:   big.NewRat(24*29, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &entity.Subscription{
+				Id:            1,
+				Price:         89900,
+				Currency:      "RUB",
+				BillingPeriod: entity.BillingMonthly,
+				StartDate:     date(2024, time.January, 1),
+				Pauses:        tt.pauses,
+			}
+			u := New(newStubRepo(), stubRates{}, stubTaxes{}, nil)
+			calc, err := u.newCostCalculator(usecase.CostFilter{Basis: tt.basis})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := calc.cost(context.Background(), sub, date(2024, time.January, 1), tt.to)
+			if err != nil {
+				t.Fatalf("cost() error = %v", err)
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("cost() = %s, want %s", got.FloatString(2), tt.want.FloatString(2))
+			}
+		})
+	}
+}
+
+func TestGetTotalCostTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     entity.Money
+		region    string
+		inclusive bool
+		discounts []entity.Discount
+		want      entity.TotalCost
+		wantErr   error
+	}{
+		{
+			name:  "no tax region",
+			price: 10000,
+			want:  entity.TotalCost{Net: 10000, Gross: 10000, Undiscounted: 10000},
+		},
+		{
+			name:   "tax added on top",
+			price:  10000,
+			region: "DE",
+			want:   entity.TotalCost{Net: 10000, Tax: 1900, Gross: 11900, Undiscounted: 11900},
+		},
+		{
+			name:      "tax included",
+			price:     11900,
+			region:    "DE",
+			inclusive: true,
+			want:      entity.TotalCost{Net: 10000, Tax: 1900, Gross: 11900, Undiscounted: 11900},
+		},
+		{
+			name:      "discount taken off before the tax",
+			price:     10000,
+			region:    "DE",
+			discounts: []entity.Discount{{SubscriptionID: 1, Kind: entity.DiscountPercent, Value: 10}},
+			want:      entity.TotalCost{Net: 9000, Tax: 1710, Gross: 10710, Undiscounted: 11900},
+		},
+		{
+			name:    "unknown tax region",
+			price:   10000,
+			region:  "FR",
+			wantErr: entity.ErrTaxRegionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStubRepo(&entity.Subscription{
+				Id:            1,
+				Price:         tt.price,
+				Currency:      "EUR",
+				BillingPeriod: entity.BillingMonthly,
+				StartDate:     date(2024, time.January, 1),
+				TaxRegion:     tt.region,
+				TaxInclusive:  tt.inclusive,
+			})
+			r.discounts = tt.discounts
+			u := New(r, stubRates{}, stubTaxes{"DE": big.NewRat(19, 100)}, nil)
+			filter := usecase.CostFilter{StartDate: "2024-01-01", EndDate: "2024-01-31", Basis: entity.CostBasisCash, Currency: "EUR"}
+
+			got, err := u.GetTotalCost(context.Background(), filter, entity.CostGroupByNone)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetTotalCost() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("GetTotalCost() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTotalCostGroups(t *testing.T) {
+	owner := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	member := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	ownerID, memberID := owner.String(), member.String()
+
+	// the owner pays 3/4 of the first subscription and the member the rest
+	// of it, the second one is the member's own
+	shared := &entity.Subscription{
+		Id:              1,
+		UserID:          owner,
+		Price:           10000,
+		Currency:        "RUB",
+		BillingPeriod:   entity.BillingMonthly,
+		StartDate:       date(2024, time.January, 1),
+		PaymentMethodID: 7,
+	}
+	own := &entity.Subscription{
+		Id:            2,
+		UserID:        member,
+		Price:         5000,
+		Currency:      "RUB",
+		BillingPeriod: entity.BillingMonthly,
+		StartDate:     date(2024, time.January, 1),
+	}
+
+	tests := []struct {
+		name    string
+		userID  *string
+		groupBy entity.CostGroupBy
+		want    map[string]entity.Money
+	}{
+		{
+			name:    "payment method",
+			groupBy: entity.CostGroupByPaymentMethod,
+			want:    map[string]entity.Money{"7": 10000, entity.CostGroupNoPaymentMethod: 5000},
+		},
+		{
+			name:    "payment method of a member's share",
+			userID:  &memberID,
+			groupBy: entity.CostGroupByPaymentMethod,
+			want:    map[string]entity.Money{"7": 2500, entity.CostGroupNoPaymentMethod: 5000},
+		},
+		{
+			name:    "user split across the members",
+			groupBy: entity.CostGroupByUserID,
+			want:    map[string]entity.Money{ownerID: 7500, memberID: 7500},
+		},
+		{
+			name:    "user limited to the member",
+			userID:  &memberID,
+			groupBy: entity.CostGroupByUserID,
+			want:    map[string]entity.Money{memberID: 7500},
+		},
+		{
+			name:    "user limited to the owner",
+			userID:  &ownerID,
+			groupBy: entity.CostGroupByUserID,
+			want:    map[string]entity.Money{ownerID: 7500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStubRepo(shared, own)
+			r.shares = []entity.Share{
+				{SubscriptionID: 1, UserID: owner, Weight: 3, Currency: "RUB"},
+				{SubscriptionID: 1, UserID: member, Weight: 1, Currency: "RUB"},
+			}
+			u := New(r, stubRates{}, stubTaxes{}, nil)
+			filter := usecase.CostFilter{UserID: tt.userID, StartDate: "2024-01-01", EndDate: "2024-01-31", Basis: entity.CostBasisCash}
+
+			total, err := u.GetTotalCost(context.Background(), filter, tt.groupBy)
+			if err != nil {
+				t.Fatalf("GetTotalCost() error = %v", err)
+			}
+
+			got := map[string]entity.Money{}
+			for _, group := range total.Groups {
+				got[group.Key] = group.Total
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTotalCost() groups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
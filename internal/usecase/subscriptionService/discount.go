@@ -0,0 +1,35 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+// AddDiscount attaches a discount to the subscription it refers to.
+func (u *SubscriptionUsecase) AddDiscount(ctx context.Context, discount *entity.Discount) error {
+	const op = "subscriptionService.AddDiscount"
+
+	if _, err := u.repo.Get(ctx, int(discount.SubscriptionID)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return u.repo.StoreDiscount(ctx, discount)
+}
+
+func (u *SubscriptionUsecase) GetDiscount(ctx context.Context, subscriptionID, id int64) (*entity.Discount, error) {
+	return u.repo.GetDiscount(ctx, subscriptionID, id)
+}
+
+func (u *SubscriptionUsecase) UpdateDiscount(ctx context.Context, discount *entity.Discount) error {
+	return u.repo.UpdateDiscount(ctx, discount)
+}
+
+func (u *SubscriptionUsecase) DeleteDiscount(ctx context.Context, subscriptionID, id int64) error {
+	return u.repo.DeleteDiscount(ctx, subscriptionID, id)
+}
+
+func (u *SubscriptionUsecase) ListDiscounts(ctx context.Context, subscriptionID int64) ([]entity.Discount, error) {
+	return u.repo.ListDiscounts(ctx, subscriptionID)
+}
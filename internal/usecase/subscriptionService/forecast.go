@@ -0,0 +1,51 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+)
+
+// Forecast projects the charges of the currently running subscriptions over
+// the given number of calendar months, the current one counted from today.
+// Paused, cancelled and expired subscriptions are left out.
+func (u *SubscriptionUsecase) Forecast(ctx context.Context, userID *string, currency string, months int) ([]entity.MonthlyCost, error) {
+	const op = "subscriptionService.Forecast"
+
+	now := time.Now()
+	start := dayOf(now)
+	end := time.Date(start.Year(), start.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+
+	filter := usecase.CostFilter{
+		UserID:   userID,
+		Basis:    entity.CostBasisCash,
+		Currency: currency,
+	}
+
+	subscriptions, err := u.costSubscriptions(ctx, filter, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	running := subscriptions[:0]
+	for _, sub := range subscriptions {
+		if status := sub.StatusAt(now); status == entity.StatusActive || status == entity.StatusTrial {
+			running = append(running, sub)
+		}
+	}
+
+	calc, err := u.newCostCalculator(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	forecast, err := monthlyCosts(ctx, calc, running, start, end, entity.CostGroupByNone)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return forecast, nil
+}
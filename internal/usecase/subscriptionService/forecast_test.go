@@ -0,0 +1,74 @@
+package subscriptionservice
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+func TestForecast(t *testing.T) {
+	today := dayOf(time.Now())
+	thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	nextMonth, lastMonth := thisMonth.AddDate(0, 1, 0), thisMonth.AddDate(0, 2, 0)
+
+	// charged on the 1st of every month since a year ago
+	running := func(status entity.Status) *entity.Subscription {
+		return &entity.Subscription{
+			Id:            1,
+			Price:         10000,
+			Currency:      "RUB",
+			BillingPeriod: entity.BillingMonthly,
+			Status:        status,
+			StartDate:     thisMonth.AddDate(-1, 0, 0),
+		}
+	}
+	active := running(entity.StatusActive)
+	trialEnded := running(entity.StatusTrial)
+	trialEnded.TrialEndDate = thisMonth.AddDate(-1, 1, 0)
+	ending := running(entity.StatusActive)
+	ending.EndDate = lastMonth
+	expired := running(entity.StatusActive)
+	expired.EndDate = today
+	paused := running(entity.StatusPaused)
+	cancelled := running(entity.StatusCancelled)
+	cancelled.EndDate = lastMonth
+
+	tests := []struct {
+		name string
+		sub  *entity.Subscription
+		// the charges of the two months after the current one
+		want []entity.Money
+	}{
+		{name: "active", sub: active, want: []entity.Money{10000, 10000}},
+		{name: "past its trial", sub: trialEnded, want: []entity.Money{10000, 10000}},
+		{name: "ending", sub: ending, want: []entity.Money{10000, 0}},
+		{name: "expired", sub: expired, want: []entity.Money{0, 0}},
+		{name: "paused", sub: paused, want: []entity.Money{0, 0}},
+		{name: "cancelled", sub: cancelled, want: []entity.Money{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := New(newStubRepo(tt.sub), stubRates{}, stubTaxes{}, nil)
+
+			forecast, err := u.Forecast(context.Background(), nil, "RUB", 3)
+			if err != nil {
+				t.Fatalf("Forecast() error = %v", err)
+			}
+			if len(forecast) != 3 || !forecast[1].Month.Equal(nextMonth) {
+				t.Fatalf("Forecast() = %+v, want 3 months from %s", forecast, thisMonth.Format("2006-01"))
+			}
+
+			var got []entity.Money
+			for _, month := range forecast[1:] {
+				got = append(got, month.Total)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Forecast() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+// BeginIdempotent reserves the key of req before it is served. A retry gets
+// the answered request stored under the key back to replay its response.
+func (u *SubscriptionUsecase) BeginIdempotent(ctx context.Context, req *entity.IdempotentRequest) (*entity.IdempotentRequest, error) {
+	const op = "subscriptionService.BeginIdempotent"
+
+	stored, err := u.idempotency.Reserve(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if stored == nil {
+		return nil, nil
+	}
+
+	if stored.RequestHash != req.RequestHash {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrIdempotencyKeyReused)
+	}
+	if !stored.Completed() {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrIdempotencyKeyInProgress)
+	}
+
+	return stored, nil
+}
+
+// CompleteIdempotent stores the response to a request whose key was reserved.
+func (u *SubscriptionUsecase) CompleteIdempotent(ctx context.Context, req *entity.IdempotentRequest) error {
+	return u.idempotency.Complete(ctx, req)
+}
+
+// ReleaseIdempotent gives up the key of a request that failed, a retry runs
+// it anew.
+func (u *SubscriptionUsecase) ReleaseIdempotent(ctx context.Context, req *entity.IdempotentRequest) error {
+	return u.idempotency.Release(ctx, req)
+}
@@ -0,0 +1,96 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/google/uuid"
+)
+
+// subscriptionKey is what the unique_subscription constraint keeps unique.
+// A service that is not in the catalog yet goes by its alias instead.
+type subscriptionKey struct {
+	userID    uuid.UUID
+	serviceID int64
+	alias     string
+	startDate int64
+}
+
+// Import adds the subscriptions in one transaction and returns why each of
+// them was left out, nil for those added. A subscription that exists, or
+// repeats one before it, fails with entity.ErrSubscriptionExists. A dry run
+// checks them all and adds none, nor any service missing from the catalog.
+func (u *SubscriptionUsecase) Import(ctx context.Context, subs []*entity.Subscription, dryRun bool) ([]error, error) {
+	const op = "subscriptionService.Import"
+
+	results := make([]error, len(subs))
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		seen := make(map[subscriptionKey]bool, len(subs))
+		for i, sub := range subs {
+			key := subscriptionKey{userID: sub.UserID, serviceID: sub.ServiceID, startDate: sub.StartDate.UnixNano()}
+			if sub.ServiceID == 0 {
+				key.alias = entity.NormalizeServiceAlias(sub.ServiceName)
+			}
+			if seen[key] {
+				results[i] = entity.ErrSubscriptionExists
+				continue
+			}
+			seen[key] = true
+
+			// nobody subscribes to a service that is not in the catalog yet
+			if sub.ServiceID != 0 {
+				exists, err := u.repo.Exists(ctx, sub.UserID, sub.ServiceID, sub.StartDate)
+				if err != nil {
+					return err
+				}
+				if exists {
+					results[i] = entity.ErrSubscriptionExists
+					continue
+				}
+			}
+
+			err := u.checkImport(ctx, sub)
+			if errors.Is(err, entity.ErrPaymentMethodNotFound) || errors.Is(err, entity.ErrTaxRegionNotFound) {
+				results[i] = err
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				continue
+			}
+
+			if err := u.resolveSubscriptionService(ctx, sub); err != nil {
+				return err
+			}
+			if err := u.repo.Store(ctx, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// checkImport checks ahead what would make storing sub fail, so that it
+// does not abort the transaction of an import.
+func (u *SubscriptionUsecase) checkImport(ctx context.Context, sub *entity.Subscription) error {
+	if err := u.checkPaymentMethod(ctx, sub); err != nil {
+		return err
+	}
+
+	if sub.TaxRegion != "" {
+		if _, err := u.taxes.TaxRate(ctx, sub.TaxRegion); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
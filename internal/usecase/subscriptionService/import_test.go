@@ -0,0 +1,83 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestImport(t *testing.T) {
+	user := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	spotify := &entity.Service{Id: 5, Name: "Spotify"}
+	existing := &entity.Subscription{Id: 1, UserID: user, ServiceID: 5, ServiceName: "Spotify", StartDate: date(2024, time.January, 1)}
+
+	rows := func() []*entity.Subscription {
+		row := func(serviceID int64, name string, start time.Time) *entity.Subscription {
+			return &entity.Subscription{UserID: user, ServiceID: serviceID, ServiceName: name, Price: 29900, Currency: "RUB", StartDate: start}
+		}
+		unknownRegion := row(5, "Spotify", date(2024, time.April, 1))
+		unknownRegion.TaxRegion = "FR"
+
+		return []*entity.Subscription{
+			row(5, "Spotify", date(2024, time.January, 1)),
+			row(0, "Netflix", date(2024, time.February, 1)),
+			row(0, " NETFLIX ", date(2024, time.February, 1)),
+			row(5, "Spotify", date(2024, time.March, 1)),
+			row(5, "Spotify", date(2024, time.March, 1)),
+			unknownRegion,
+		}
+	}
+	wantErrs := []error{
+		entity.ErrSubscriptionExists, // stored already
+		nil,
+		entity.ErrSubscriptionExists, // repeats the row before it, by the alias of a new service
+		nil,
+		entity.ErrSubscriptionExists, // repeats the row before it
+		entity.ErrTaxRegionNotFound,
+	}
+
+	tests := []struct {
+		name       string
+		dryRun     bool
+		wantWrites int
+		wantSubs   int
+	}{
+		{name: "dry run", dryRun: true, wantWrites: 0, wantSubs: 1},
+		// Netflix added to the catalog and the two subscriptions
+		{name: "import", wantWrites: 3, wantSubs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStubRepo(existing)
+			r.services[entity.NormalizeServiceAlias(spotify.Name)] = spotify
+			u := New(r, stubRates{}, stubTaxes{"DE": big.NewRat(19, 100)}, nil)
+
+			results, err := u.Import(context.Background(), rows(), tt.dryRun)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			if len(results) != len(wantErrs) {
+				t.Fatalf("Import() = %d results, want %d", len(results), len(wantErrs))
+			}
+			for i, err := range results {
+				if !errors.Is(err, wantErrs[i]) {
+					t.Errorf("row %d error = %v, want %v", i, err, wantErrs[i])
+				}
+			}
+
+			if r.writes != tt.wantWrites || len(r.subs) != tt.wantSubs {
+				t.Errorf("Import() left %d writes and %d subscriptions, want %d and %d", r.writes, len(r.subs), tt.wantWrites, tt.wantSubs)
+			}
+			if _, err := r.FindService(context.Background(), "Netflix"); (err == nil) == tt.dryRun {
+				t.Errorf("Netflix in the catalog = %v after a dry run %v", err == nil, tt.dryRun)
+			}
+		})
+	}
+}
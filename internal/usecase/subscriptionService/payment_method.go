@@ -0,0 +1,80 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo/persistence"
+	"github.com/google/uuid"
+)
+
+func (u *SubscriptionUsecase) StorePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error {
+	return u.repo.StorePaymentMethod(ctx, method)
+}
+
+func (u *SubscriptionUsecase) GetPaymentMethod(ctx context.Context, userID uuid.UUID, id int64) (*entity.PaymentMethod, error) {
+	return u.repo.GetPaymentMethod(ctx, userID, id)
+}
+
+func (u *SubscriptionUsecase) UpdatePaymentMethod(ctx context.Context, method *entity.PaymentMethod) error {
+	return u.repo.UpdatePaymentMethod(ctx, method)
+}
+
+func (u *SubscriptionUsecase) ListPaymentMethods(ctx context.Context, userID uuid.UUID) ([]entity.PaymentMethod, error) {
+	return u.repo.ListPaymentMethods(ctx, userID)
+}
+
+// ListPaymentMethodSubscriptions returns the subscriptions paid with a
+// payment method, which would be left without one if it was deleted.
+func (u *SubscriptionUsecase) ListPaymentMethodSubscriptions(ctx context.Context, userID uuid.UUID, id int64) ([]*entity.Subscription, error) {
+	const op = "subscriptionService.ListPaymentMethodSubscriptions"
+
+	if _, err := u.repo.GetPaymentMethod(ctx, userID, id); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	subscriptions, err := u.repo.List(ctx, persistence.WithPaymentMethod(id), persistence.WithLimit(0))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscriptions, nil
+}
+
+// DeletePaymentMethod removes a payment method and returns the subscriptions
+// that were paid with it and are now left without one.
+func (u *SubscriptionUsecase) DeletePaymentMethod(ctx context.Context, userID uuid.UUID, id int64) ([]*entity.Subscription, error) {
+	const op = "subscriptionService.DeletePaymentMethod"
+
+	var orphaned []*entity.Subscription
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		orphaned, err = u.ListPaymentMethodSubscriptions(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+
+		return u.repo.DeletePaymentMethod(ctx, userID, id)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, sub := range orphaned {
+		sub.PaymentMethodID = 0
+	}
+
+	return orphaned, nil
+}
+
+// checkPaymentMethod makes sure a subscription is paid with a payment
+// method of its owner.
+func (u *SubscriptionUsecase) checkPaymentMethod(ctx context.Context, sub *entity.Subscription) error {
+	if sub.PaymentMethodID == 0 {
+		return nil
+	}
+
+	_, err := u.repo.GetPaymentMethod(ctx, sub.UserID, sub.PaymentMethodID)
+	return err
+}
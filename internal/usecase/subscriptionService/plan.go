@@ -0,0 +1,45 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+func (u *SubscriptionUsecase) StorePlan(ctx context.Context, plan *entity.Plan) error {
+	return u.repo.StorePlan(ctx, plan)
+}
+
+func (u *SubscriptionUsecase) GetPlan(ctx context.Context, serviceID, id int64) (*entity.Plan, error) {
+	return u.repo.GetPlan(ctx, serviceID, id)
+}
+
+func (u *SubscriptionUsecase) UpdatePlan(ctx context.Context, plan *entity.Plan) error {
+	return u.repo.UpdatePlan(ctx, plan)
+}
+
+func (u *SubscriptionUsecase) DeletePlan(ctx context.Context, serviceID, id int64) error {
+	return u.repo.DeletePlan(ctx, serviceID, id)
+}
+
+// ListPlans returns the plans of a catalog service.
+func (u *SubscriptionUsecase) ListPlans(ctx context.Context, serviceID int64) ([]entity.Plan, error) {
+	const op = "subscriptionService.ListPlans"
+
+	if _, err := u.repo.GetService(ctx, serviceID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	plans, err := u.repo.ListPlans(ctx, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return plans, nil
+}
+
+func (u *SubscriptionUsecase) PlanUsage(ctx context.Context, serviceID *int64, on time.Time) ([]entity.PlanUsage, error) {
+	return u.repo.PlanUsage(ctx, serviceID, on)
+}
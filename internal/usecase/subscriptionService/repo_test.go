@@ -0,0 +1,277 @@
+package subscriptionservice
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo/persistence"
+	"github.com/google/uuid"
+)
+
+// stubRepo keeps subscriptions in memory, listing those of a user along
+// with the ones shared with them. A transaction takes a snapshot of
+// them when it starts and puts it back when it fails, a nested one included.
+// What the tests do not reach is left to the embedded nil interface.
+type stubRepo struct {
+	repo.SubscriptionRepo
+
+	subs      map[int64]*entity.Subscription
+	services  map[string]*entity.Service // by alias
+	pauses    []entity.Pause
+	shares    []entity.Share
+	discounts []entity.Discount
+	changes   []entity.PriceChange
+	budgets   []entity.Budget
+	nextID    int64
+
+	// writes counts the rows stored, updated or deleted, services included
+	writes int
+	// listed holds the options of the last List call
+	listed persistence.ListOptions
+}
+
+func newStubRepo(subs ...*entity.Subscription) *stubRepo {
+	r := &stubRepo{
+		subs:     map[int64]*entity.Subscription{},
+		services: map[string]*entity.Service{},
+	}
+	for _, sub := range subs {
+		r.nextID = max(r.nextID, sub.Id)
+		if sub.Version == 0 {
+			sub.Version = 1
+		}
+		stored := *sub
+		r.subs[sub.Id] = &stored
+	}
+	return r
+}
+
+func (r *stubRepo) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	subs := make(map[int64]*entity.Subscription, len(r.subs))
+	for id, sub := range r.subs {
+		stored := *sub
+		subs[id] = &stored
+	}
+	services := make(map[string]*entity.Service, len(r.services))
+	for alias, service := range r.services {
+		services[alias] = service
+	}
+	pauses, nextID, writes := slices.Clone(r.pauses), r.nextID, r.writes
+
+	if err := fn(ctx); err != nil {
+		r.subs, r.services, r.pauses, r.nextID, r.writes = subs, services, pauses, nextID, writes
+		return err
+	}
+	return nil
+}
+
+func (r *stubRepo) Store(_ context.Context, sub *entity.Subscription) error {
+	for _, stored := range r.subs {
+		if stored.UserID == sub.UserID && stored.ServiceID == sub.ServiceID && stored.StartDate.Equal(sub.StartDate) {
+			return entity.ErrSubscriptionExists
+		}
+	}
+
+	r.nextID++
+	sub.Id = r.nextID
+	sub.Version = 1
+	stored := *sub
+	r.subs[sub.Id] = &stored
+	r.writes++
+	return nil
+}
+
+func (r *stubRepo) Get(_ context.Context, id int) (*entity.Subscription, error) {
+	stored, ok := r.subs[int64(id)]
+	if !ok {
+		return nil, entity.ErrSubscriptionNotFound
+	}
+	sub := *stored
+	return &sub, nil
+}
+
+func (r *stubRepo) Update(_ context.Context, sub *entity.Subscription) error {
+	stored, ok := r.subs[sub.Id]
+	if !ok {
+		return entity.ErrSubscriptionNotFound
+	}
+	if stored.Version != sub.Version {
+		return entity.ErrVersionMismatch
+	}
+
+	sub.Version++
+	updated := *sub
+	updated.PriceChanges, updated.Pauses, updated.Shares, updated.Discounts, updated.Tags = nil, nil, nil, nil, nil
+	r.subs[sub.Id] = &updated
+	r.writes++
+	return nil
+}
+
+func (r *stubRepo) Delete(_ context.Context, id int, versions ...int64) error {
+	stored, ok := r.subs[int64(id)]
+	if !ok {
+		return entity.ErrSubscriptionNotFound
+	}
+	if len(versions) > 0 && !slices.Contains(versions, stored.Version) {
+		return entity.ErrVersionMismatch
+	}
+
+	delete(r.subs, int64(id))
+	r.writes++
+	return nil
+}
+
+func (r *stubRepo) List(_ context.Context, opts ...persistence.ListOption) ([]*entity.Subscription, error) {
+	r.listed = persistence.ListOptions{}
+	for _, opt := range opts {
+		opt(&r.listed)
+	}
+
+	var subs []*entity.Subscription
+	for _, stored := range r.subs {
+		if r.listed.UserID != nil && stored.UserID != *r.listed.UserID && !r.sharedWith(stored.Id, *r.listed.UserID) {
+			continue
+		}
+		if r.listed.ServiceName != nil && !strings.EqualFold(stored.ServiceName, *r.listed.ServiceName) {
+			continue
+		}
+		if len(r.listed.Tags) > 0 && !slices.ContainsFunc(stored.TagNames(), func(name string) bool { return slices.Contains(r.listed.Tags, name) }) {
+			continue
+		}
+		sub := *stored
+		subs = append(subs, &sub)
+	}
+	slices.SortFunc(subs, func(a, b *entity.Subscription) int { return int(a.Id - b.Id) })
+
+	subs = subs[min(r.listed.Offset, len(subs)):]
+	if r.listed.Limit > 0 && len(subs) > r.listed.Limit {
+		subs = subs[:r.listed.Limit]
+	}
+	return subs, nil
+}
+
+func (r *stubRepo) sharedWith(subscriptionID int64, userID uuid.UUID) bool {
+	return slices.ContainsFunc(r.shares, func(s entity.Share) bool {
+		return s.SubscriptionID == subscriptionID && s.UserID == userID
+	})
+}
+
+func (r *stubRepo) Count(ctx context.Context, opts ...persistence.ListOption) (int, error) {
+	subs, err := r.List(ctx, append(opts, persistence.WithLimit(0), persistence.WithOffset(0))...)
+	return len(subs), err
+}
+
+func (r *stubRepo) Exists(_ context.Context, userID uuid.UUID, serviceID int64, startDate time.Time) (bool, error) {
+	for _, stored := range r.subs {
+		if stored.UserID == userID && stored.ServiceID == serviceID && stored.StartDate.Equal(startDate) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *stubRepo) StorePause(_ context.Context, pause *entity.Pause) error {
+	r.pauses = append(r.pauses, *pause)
+	r.writes++
+	return nil
+}
+
+func (r *stubRepo) ClosePause(_ context.Context, subscriptionID int64, end time.Time) error {
+	for i, pause := range r.pauses {
+		if pause.SubscriptionID == subscriptionID && pause.EndDate.IsZero() {
+			r.pauses[i].EndDate = end
+			r.writes++
+		}
+	}
+	return nil
+}
+
+func (r *stubRepo) ListPauses(_ context.Context, subscriptionIDs ...int64) ([]entity.Pause, error) {
+	return ofSubscriptions(r.pauses, subscriptionIDs, func(p entity.Pause) int64 { return p.SubscriptionID }), nil
+}
+
+func (r *stubRepo) ListPriceChanges(_ context.Context, subscriptionIDs ...int64) ([]entity.PriceChange, error) {
+	return ofSubscriptions(r.changes, subscriptionIDs, func(c entity.PriceChange) int64 { return c.SubscriptionID }), nil
+}
+
+func (r *stubRepo) ListShares(_ context.Context, subscriptionIDs ...int64) ([]entity.Share, error) {
+	return ofSubscriptions(r.shares, subscriptionIDs, func(s entity.Share) int64 { return s.SubscriptionID }), nil
+}
+
+func (r *stubRepo) ListDiscounts(_ context.Context, subscriptionIDs ...int64) ([]entity.Discount, error) {
+	return ofSubscriptions(r.discounts, subscriptionIDs, func(d entity.Discount) int64 { return d.SubscriptionID }), nil
+}
+
+func (r *stubRepo) ListSubscriptionTags(context.Context, ...int64) (map[int64][]entity.Tag, error) {
+	return map[int64][]entity.Tag{}, nil
+}
+
+func (r *stubRepo) ListBudgets(_ context.Context, userID uuid.UUID) ([]entity.Budget, error) {
+	var budgets []entity.Budget
+	for _, budget := range r.budgets {
+		if budget.UserID == userID {
+			budgets = append(budgets, budget)
+		}
+	}
+	return budgets, nil
+}
+
+func (r *stubRepo) FindService(_ context.Context, name string) (*entity.Service, error) {
+	service, ok := r.services[entity.NormalizeServiceAlias(name)]
+	if !ok {
+		return nil, entity.ErrServiceNotFound
+	}
+	return service, nil
+}
+
+func (r *stubRepo) StoreService(_ context.Context, service *entity.Service) error {
+	r.nextID++
+	service.Id = r.nextID
+	for _, alias := range append(service.Aliases, entity.NormalizeServiceAlias(service.Name)) {
+		r.services[alias] = service
+	}
+	r.writes++
+	return nil
+}
+
+func ofSubscriptions[T any](rows []T, subscriptionIDs []int64, subscriptionID func(T) int64) []T {
+	var matching []T
+	for _, row := range rows {
+		if slices.Contains(subscriptionIDs, subscriptionID(row)) {
+			matching = append(matching, row)
+		}
+	}
+	return matching
+}
+
+// stubRates serves the rates keyed by base, quote and date, such as
+// "USD/RUB 2024-01-31", as the rate effective on that day only.
+type stubRates map[string]*big.Rat
+
+func (r stubRates) Rate(_ context.Context, base, quote string, on time.Time) (*big.Rat, error) {
+	rate, ok := r[base+"/"+quote+" "+on.Format("2006-01-02")]
+	if !ok {
+		return nil, entity.ErrExchangeRateNotFound
+	}
+	return new(big.Rat).Set(rate), nil
+}
+
+// stubTaxes serves the tax rates keyed by region.
+type stubTaxes map[string]*big.Rat
+
+func (t stubTaxes) TaxRate(_ context.Context, region string) (*big.Rat, error) {
+	rate, ok := t[region]
+	if !ok {
+		return nil, entity.ErrTaxRegionNotFound
+	}
+	return new(big.Rat).Set(rate), nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
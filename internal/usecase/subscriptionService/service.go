@@ -2,42 +2,220 @@ package subscriptionservice
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo"
 	"github.com/M1r0-dev/Subscription-Aggregator/internal/repo/persistence"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
 )
 
 type SubscriptionUsecase struct {
-	repo repo.SubscriptionRepo
+	repo        repo.SubscriptionRepo
+	rates       repo.ExchangeRateProvider
+	taxes       repo.TaxRateProvider
+	idempotency repo.IdempotencyStore
 }
 
-func New(repo repo.SubscriptionRepo) *SubscriptionUsecase {
+func New(repo repo.SubscriptionRepo, rates repo.ExchangeRateProvider, taxes repo.TaxRateProvider, idempotency repo.IdempotencyStore) *SubscriptionUsecase {
 	return &SubscriptionUsecase{
-		repo: repo,
+		repo:        repo,
+		rates:       rates,
+		taxes:       taxes,
+		idempotency: idempotency,
 	}
 }
 
+// Store adds a subscription, which has to be paid with a payment method of
+// its owner if any.
 func (u *SubscriptionUsecase) Store(ctx context.Context, sub *entity.Subscription) error {
-	return u.repo.Store(ctx, sub)
+	const op = "subscriptionService.Store"
+
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.resolveSubscriptionService(ctx, sub); err != nil {
+			return err
+		}
+		if err := u.checkPaymentMethod(ctx, sub); err != nil {
+			return err
+		}
+		return u.repo.Store(ctx, sub)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
 }
 
+// Get returns a subscription along with its tags and history, so that its
+// next billing date can be worked out.
 func (u *SubscriptionUsecase) Get(ctx context.Context, id int) (*entity.Subscription, error) {
-	return u.repo.Get(ctx, id)
+	const op = "subscriptionService.Get"
+
+	sub, err := u.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.loadHistory(ctx, []*entity.Subscription{sub}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sub, nil
 }
 
 func (u *SubscriptionUsecase) Update(ctx context.Context, sub *entity.Subscription) error {
-	return u.repo.Update(ctx, sub)
+	const op = "subscriptionService.Update"
+
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.resolveSubscriptionService(ctx, sub); err != nil {
+			return err
+		}
+		if err := u.checkPaymentMethod(ctx, sub); err != nil {
+			return err
+		}
+		return u.repo.Update(ctx, sub)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
 }
 
-func (u *SubscriptionUsecase) Delete(ctx context.Context, id int) error {
-	return u.repo.Delete(ctx, id)
+// Delete removes a subscription, only at one of the given versions if any.
+func (u *SubscriptionUsecase) Delete(ctx context.Context, id int, versions ...int64) error {
+	return u.repo.Delete(ctx, id, versions...)
 }
 
-func (u *SubscriptionUsecase) List(ctx context.Context, opts ...persistence.ListOption) ([]*entity.Subscription, error) {
-	return u.repo.List(ctx, opts...)
+// List returns a page of the matching subscriptions along with their
+// history, so that their current price, the share of every member and the
+// next billing date can be worked out, and the number of matches.
+func (u *SubscriptionUsecase) List(
+	ctx context.Context,
+	page usecase.ListPage,
+	opts ...persistence.ListOption,
+) ([]*entity.Subscription, int, error) {
+	const op = "subscriptionService.List"
+
+	if page.SortBy == usecase.SortByNextBillingDate || page.NextBillingFrom != nil || page.NextBillingTo != nil {
+		subscriptions, total, err := u.listByNextBilling(ctx, page, opts...)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", op, err)
+		}
+		return subscriptions, total, nil
+	}
+
+	total, err := u.repo.Count(ctx, opts...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	opts = append(opts,
+		persistence.WithSort(page.SortBy, page.SortOrder),
+		persistence.WithLimit(page.PageSize),
+		persistence.WithOffset((page.Page-1)*page.PageSize),
+	)
+
+	subscriptions, err := u.repo.List(ctx, opts...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.loadHistory(ctx, subscriptions); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscriptions, total, nil
+}
+
+// maxNextBillingList is the most subscriptions listByNextBilling loads.
+const maxNextBillingList = 5000
+
+// listByNextBilling filters and sorts the matching subscriptions by their
+// next billing date, which is not stored, so all of them are loaded and the
+// page is cut out afterwards. Subscriptions that are not billed anymore come
+// last whatever the order. More than maxNextBillingList matching
+// subscriptions are refused with ErrTooManySubscriptions rather than loaded.
+func (u *SubscriptionUsecase) listByNextBilling(
+	ctx context.Context,
+	page usecase.ListPage,
+	opts ...persistence.ListOption,
+) ([]*entity.Subscription, int, error) {
+	opts = append(opts, persistence.WithLimit(maxNextBillingList+1))
+	if page.SortBy != usecase.SortByNextBillingDate {
+		opts = append(opts, persistence.WithSort(page.SortBy, page.SortOrder))
+	}
+
+	subscriptions, err := u.repo.List(ctx, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(subscriptions) > maxNextBillingList {
+		return nil, 0, entity.ErrTooManySubscriptions
+	}
+
+	if err := u.loadHistory(ctx, subscriptions); err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	next := make(map[int64]time.Time, len(subscriptions))
+	matched := subscriptions[:0]
+	for _, sub := range subscriptions {
+		date, ok := sub.NextBillingDate(now)
+		if page.NextBillingFrom != nil && (!ok || date.Before(*page.NextBillingFrom)) {
+			continue
+		}
+		if page.NextBillingTo != nil && (!ok || date.After(*page.NextBillingTo)) {
+			continue
+		}
+		if ok {
+			next[sub.Id] = date
+		}
+		matched = append(matched, sub)
+	}
+
+	if page.SortBy == usecase.SortByNextBillingDate {
+		sort.SliceStable(matched, func(i, j int) bool {
+			a, aok := next[matched[i].Id]
+			b, bok := next[matched[j].Id]
+			if !aok || !bok {
+				return aok && !bok
+			}
+			if page.SortOrder == "asc" {
+				return a.Before(b)
+			}
+			return b.Before(a)
+		})
+	}
+
+	total := len(matched)
+	start := min((page.Page-1)*page.PageSize, total)
+	end := min(start+page.PageSize, total)
+
+	return matched[start:end], total, nil
+}
+
+// AddPriceChange records a new price for a subscription from the change's
+// effective date on.
+func (u *SubscriptionUsecase) AddPriceChange(ctx context.Context, change *entity.PriceChange) error {
+	const op = "subscriptionService.AddPriceChange"
+
+	sub, err := u.repo.Get(ctx, int(change.SubscriptionID))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if change.EffectiveDate.Before(sub.StartDate) {
+		return fmt.Errorf("%s: %w", op, entity.ErrPriceChangeBeforeStart)
+	}
+
+	return u.repo.StorePriceChange(ctx, change)
 }
 
-func (u *SubscriptionUsecase) GetTotalCost(ctx context.Context, userID *string, serviceName *string, startDate, endDate string) (uint64, error) {
-    return u.repo.GetTotalCost(ctx, userID, serviceName, startDate, endDate)
+func (u *SubscriptionUsecase) ListPriceChanges(ctx context.Context, subscriptionID int64) ([]entity.PriceChange, error) {
+	return u.repo.ListPriceChanges(ctx, subscriptionID)
 }
@@ -0,0 +1,94 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/usecase"
+)
+
+func TestListByNextBilling(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	// yearly subscriptions billed next in 3, 1 and 2 days, and an ended one
+	yearly := func(id int64, days int) *entity.Subscription {
+		return &entity.Subscription{Id: id, BillingPeriod: entity.BillingYearly, StartDate: today.AddDate(-1, 0, days)}
+	}
+	ended := yearly(4, 5)
+	ended.StartDate = today.AddDate(-2, 0, 0)
+	ended.EndDate = today.AddDate(0, -1, 0)
+	subs := []*entity.Subscription{yearly(1, 3), yearly(2, 1), yearly(3, 2), ended}
+
+	from, to := today.AddDate(0, 0, 2), today.AddDate(0, 0, 3)
+
+	tests := []struct {
+		name      string
+		page      usecase.ListPage
+		want      []int64
+		wantTotal int
+	}{
+		{
+			name:      "ascending",
+			page:      usecase.ListPage{SortBy: usecase.SortByNextBillingDate, SortOrder: "asc", Page: 1, PageSize: 10},
+			want:      []int64{2, 3, 1, 4},
+			wantTotal: 4,
+		},
+		{
+			name:      "descending, the ended one last",
+			page:      usecase.ListPage{SortBy: usecase.SortByNextBillingDate, SortOrder: "desc", Page: 1, PageSize: 10},
+			want:      []int64{1, 3, 2, 4},
+			wantTotal: 4,
+		},
+		{
+			name:      "second page",
+			page:      usecase.ListPage{SortBy: usecase.SortByNextBillingDate, SortOrder: "asc", Page: 2, PageSize: 3},
+			want:      []int64{4},
+			wantTotal: 4,
+		},
+		{
+			name:      "within a range",
+			page:      usecase.ListPage{SortBy: "start_date", SortOrder: "asc", Page: 1, PageSize: 10, NextBillingFrom: &from, NextBillingTo: &to},
+			want:      []int64{1, 3},
+			wantTotal: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStubRepo(subs...)
+			u := New(r, stubRates{}, stubTaxes{}, nil)
+
+			got, total, err := u.List(context.Background(), tt.page)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			var ids []int64
+			for _, sub := range got {
+				ids = append(ids, sub.Id)
+			}
+			if !slices.Equal(ids, tt.want) || total != tt.wantTotal {
+				t.Errorf("List() = %v, %d, want %v, %d", ids, total, tt.want, tt.wantTotal)
+			}
+			if r.listed.Limit != maxNextBillingList+1 {
+				t.Errorf("List() loaded up to %d subscriptions, want %d", r.listed.Limit, maxNextBillingList+1)
+			}
+		})
+	}
+}
+
+func TestListByNextBillingTooMany(t *testing.T) {
+	subs := make([]*entity.Subscription, maxNextBillingList+1)
+	for i := range subs {
+		subs[i] = &entity.Subscription{Id: int64(i + 1), BillingPeriod: entity.BillingMonthly, StartDate: date(2024, time.January, 1)}
+	}
+	u := New(newStubRepo(subs...), stubRates{}, stubTaxes{}, nil)
+
+	page := usecase.ListPage{SortBy: usecase.SortByNextBillingDate, SortOrder: "asc", Page: 1, PageSize: 10}
+	if _, _, err := u.List(context.Background(), page); !errors.Is(err, entity.ErrTooManySubscriptions) {
+		t.Errorf("List() error = %v, want %v", err, entity.ErrTooManySubscriptions)
+	}
+}
@@ -0,0 +1,35 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+// ReplaceShares sets the members a subscription is split across, an empty
+// list leaves the whole price to the owner.
+func (u *SubscriptionUsecase) ReplaceShares(ctx context.Context, subscriptionID int64, shares []entity.Share) ([]entity.Share, error) {
+	const op = "subscriptionService.ReplaceShares"
+
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := u.repo.Get(ctx, int(subscriptionID)); err != nil {
+			return err
+		}
+
+		for i := range shares {
+			shares[i].SubscriptionID = subscriptionID
+		}
+
+		return u.repo.ReplaceShares(ctx, subscriptionID, shares)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return shares, nil
+}
+
+func (u *SubscriptionUsecase) ListShares(ctx context.Context, subscriptionID int64) ([]entity.Share, error) {
+	return u.repo.ListShares(ctx, subscriptionID)
+}
@@ -0,0 +1,97 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+// Pause stops charging a subscription from at until it is resumed.
+func (u *SubscriptionUsecase) Pause(ctx context.Context, id int, at time.Time) (*entity.Subscription, error) {
+	const op = "subscriptionService.Pause"
+
+	sub, err := u.changeStatus(ctx, id, entity.StatusPaused, at, func(ctx context.Context, sub *entity.Subscription) error {
+		return u.repo.StorePause(ctx, &entity.Pause{SubscriptionID: sub.Id, StartDate: at})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sub, nil
+}
+
+// Resume closes the open pause of a subscription at at. One resumed within
+// its free trial goes back to the trial.
+func (u *SubscriptionUsecase) Resume(ctx context.Context, id int, at time.Time) (*entity.Subscription, error) {
+	const op = "subscriptionService.Resume"
+
+	sub, err := u.changeStatus(ctx, id, entity.StatusActive, at, func(ctx context.Context, sub *entity.Subscription) error {
+		if at.Before(sub.TrialEndDate) {
+			sub.Status = entity.StatusTrial
+		}
+		return u.repo.ClosePause(ctx, sub.Id, at)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sub, nil
+}
+
+// Cancel ends a subscription at at unless it is set to end earlier. One that
+// starts after at cannot be cancelled, it would end before it starts.
+func (u *SubscriptionUsecase) Cancel(ctx context.Context, id int, at time.Time) (*entity.Subscription, error) {
+	const op = "subscriptionService.Cancel"
+
+	sub, err := u.changeStatus(ctx, id, entity.StatusCancelled, at, func(ctx context.Context, sub *entity.Subscription) error {
+		if at.Before(sub.StartDate) {
+			return entity.ErrSubscriptionNotStarted
+		}
+		if sub.EndDate.IsZero() || at.Before(sub.EndDate) {
+			sub.EndDate = at
+		}
+		return u.repo.ClosePause(ctx, sub.Id, at)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sub, nil
+}
+
+// changeStatus moves a subscription to the given status if its status at
+// at allows it, apply records whatever else the transition changes. The
+// subscription comes back as Get returns it, with its history.
+func (u *SubscriptionUsecase) changeStatus(
+	ctx context.Context,
+	id int,
+	to entity.Status,
+	at time.Time,
+	apply func(ctx context.Context, sub *entity.Subscription) error,
+) (*entity.Subscription, error) {
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		sub, err := u.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		from := sub.StatusAt(at)
+		if !from.CanTransitionTo(to) {
+			return fmt.Errorf("%w: %s to %s", entity.ErrInvalidStatusTransition, from, to)
+		}
+		sub.Status = to
+
+		if err := apply(ctx, sub); err != nil {
+			return err
+		}
+
+		return u.repo.Update(ctx, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.Get(ctx, id)
+}
@@ -0,0 +1,111 @@
+package subscriptionservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+func TestChangeStatus(t *testing.T) {
+	trial := &entity.Subscription{
+		Id:             1,
+		BillingPeriod:  entity.BillingMonthly,
+		Status:         entity.StatusPaused,
+		StartDate:      date(2024, time.January, 1),
+		TrialStartDate: date(2024, time.January, 1),
+		TrialEndDate:   date(2024, time.February, 1),
+	}
+	active := &entity.Subscription{
+		Id:            1,
+		BillingPeriod: entity.BillingMonthly,
+		Status:        entity.StatusActive,
+		StartDate:     date(2024, time.January, 1),
+		EndDate:       date(2024, time.June, 1),
+	}
+
+	tests := []struct {
+		name       string
+		sub        *entity.Subscription
+		change     func(u *SubscriptionUsecase, ctx context.Context, id int, at time.Time) (*entity.Subscription, error)
+		at         time.Time
+		wantStatus entity.Status
+		wantEnd    time.Time
+		wantErr    error
+	}{
+		{
+			name:       "resumed within the trial",
+			sub:        trial,
+			change:     (*SubscriptionUsecase).Resume,
+			at:         date(2024, time.January, 20),
+			wantStatus: entity.StatusTrial,
+		},
+		{
+			name:       "resumed after the trial",
+			sub:        trial,
+			change:     (*SubscriptionUsecase).Resume,
+			at:         date(2024, time.February, 1),
+			wantStatus: entity.StatusActive,
+		},
+		{
+			name:       "paused",
+			sub:        active,
+			change:     (*SubscriptionUsecase).Pause,
+			at:         date(2024, time.March, 1),
+			wantStatus: entity.StatusPaused,
+			wantEnd:    date(2024, time.June, 1),
+		},
+		{
+			name:       "cancelled before its end date",
+			sub:        active,
+			change:     (*SubscriptionUsecase).Cancel,
+			at:         date(2024, time.March, 1),
+			wantStatus: entity.StatusCancelled,
+			wantEnd:    date(2024, time.March, 1),
+		},
+		{
+			name:    "cancelled before it starts",
+			sub:     active,
+			change:  (*SubscriptionUsecase).Cancel,
+			at:      date(2023, time.December, 1),
+			wantErr: entity.ErrSubscriptionNotStarted,
+		},
+		{
+			name:    "resumed while not paused",
+			sub:     active,
+			change:  (*SubscriptionUsecase).Resume,
+			at:      date(2024, time.March, 1),
+			wantErr: entity.ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStubRepo(tt.sub)
+			u := New(r, nil, nil, nil)
+
+			got, err := tt.change(u, context.Background(), 1, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if r.writes != 0 {
+					t.Errorf("%d writes despite failing", r.writes)
+				}
+				return
+			}
+
+			if got.Status != tt.wantStatus || !got.EndDate.Equal(tt.wantEnd) {
+				t.Errorf("status = %s, end date = %v, want %s, %v", got.Status, got.EndDate, tt.wantStatus, tt.wantEnd)
+			}
+			if got.Version != 2 {
+				t.Errorf("version = %d, want 2", got.Version)
+			}
+			if tt.wantStatus == entity.StatusPaused && len(got.Pauses) != 1 {
+				t.Errorf("pauses = %v, want the new pause with the subscription", got.Pauses)
+			}
+		})
+	}
+}
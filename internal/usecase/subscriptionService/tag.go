@@ -0,0 +1,50 @@
+package subscriptionservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/M1r0-dev/Subscription-Aggregator/internal/entity"
+)
+
+func (u *SubscriptionUsecase) StoreTag(ctx context.Context, tag *entity.Tag) error {
+	return u.repo.StoreTag(ctx, tag)
+}
+
+func (u *SubscriptionUsecase) GetTag(ctx context.Context, id int64) (*entity.Tag, error) {
+	return u.repo.GetTag(ctx, id)
+}
+
+func (u *SubscriptionUsecase) UpdateTag(ctx context.Context, tag *entity.Tag) error {
+	return u.repo.UpdateTag(ctx, tag)
+}
+
+func (u *SubscriptionUsecase) DeleteTag(ctx context.Context, id int64) error {
+	return u.repo.DeleteTag(ctx, id)
+}
+
+func (u *SubscriptionUsecase) ListTags(ctx context.Context) ([]entity.Tag, error) {
+	return u.repo.ListTags(ctx)
+}
+
+// ReplaceSubscriptionTags labels a subscription with the named tags only,
+// creating the tags that do not exist yet.
+func (u *SubscriptionUsecase) ReplaceSubscriptionTags(ctx context.Context, subscriptionID int64, names []string) ([]entity.Tag, error) {
+	const op = "subscriptionService.ReplaceSubscriptionTags"
+
+	var tags []entity.Tag
+	err := u.repo.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := u.repo.Get(ctx, int(subscriptionID)); err != nil {
+			return err
+		}
+
+		var err error
+		tags, err = u.repo.ReplaceSubscriptionTags(ctx, subscriptionID, names)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,5 @@
+-- migrations/002_add_billing_period.down.sql
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS valid_billing_period_days,
+    DROP COLUMN IF EXISTS billing_period_days,
+    DROP COLUMN IF EXISTS billing_period;
@@ -0,0 +1,7 @@
+-- migrations/002_add_billing_period.up.sql
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly'
+        CHECK (billing_period IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom')),
+    ADD COLUMN billing_period_days INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT valid_billing_period_days
+        CHECK ((billing_period = 'custom') = (billing_period_days > 0));
@@ -0,0 +1,5 @@
+-- migrations/003_add_currency.down.sql
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,12 @@
+-- migrations/003_add_currency.up.sql
+ALTER TABLE subscriptions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$');
+
+CREATE TABLE exchange_rates (
+    base_currency CHAR(3) NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+    quote_currency CHAR(3) NOT NULL CHECK (quote_currency ~ '^[A-Z]{3}$'),
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    effective_date DATE NOT NULL,
+
+    PRIMARY KEY (base_currency, quote_currency, effective_date)
+);
@@ -0,0 +1,2 @@
+-- migrations/004_create_subscription_price_changes_table.down.sql
+DROP TABLE IF EXISTS subscription_price_changes;
@@ -0,0 +1,10 @@
+-- migrations/004_create_subscription_price_changes_table.up.sql
+CREATE TABLE subscription_price_changes (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    price BIGINT NOT NULL CHECK (price >= 0),
+    effective_date TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_price_change UNIQUE (subscription_id, effective_date)
+);
@@ -0,0 +1,5 @@
+-- migrations/005_add_subscription_status.down.sql
+DROP TABLE IF EXISTS subscription_pauses;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,17 @@
+-- migrations/005_add_subscription_status.up.sql
+ALTER TABLE subscriptions
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('trial', 'active', 'paused', 'cancelled', 'expired'));
+
+CREATE TABLE subscription_pauses (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    start_date TIMESTAMPTZ NOT NULL,
+    end_date TIMESTAMPTZ,
+
+    CONSTRAINT valid_pause_dates CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_subscription_pauses_subscription_id ON subscription_pauses(subscription_id);
+-- at most one pause per subscription may still be open
+CREATE UNIQUE INDEX idx_subscription_pauses_open ON subscription_pauses(subscription_id) WHERE end_date IS NULL;
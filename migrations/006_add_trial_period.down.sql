@@ -0,0 +1,5 @@
+-- migrations/006_add_trial_period.down.sql
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS valid_trial_dates,
+    DROP COLUMN IF EXISTS trial_end_date,
+    DROP COLUMN IF EXISTS trial_start_date;
@@ -0,0 +1,8 @@
+-- migrations/006_add_trial_period.up.sql
+ALTER TABLE subscriptions
+    ADD COLUMN trial_start_date TIMESTAMPTZ,
+    ADD COLUMN trial_end_date TIMESTAMPTZ,
+    ADD CONSTRAINT valid_trial_dates
+        CHECK ((trial_start_date IS NULL) = (trial_end_date IS NULL) AND trial_end_date > trial_start_date);
+
+CREATE INDEX idx_subscriptions_trial_end_date ON subscriptions(trial_end_date) WHERE trial_end_date IS NOT NULL;
@@ -0,0 +1,2 @@
+-- migrations/007_create_subscription_shares_table.down.sql
+DROP TABLE IF EXISTS subscription_shares;
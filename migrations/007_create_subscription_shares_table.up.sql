@@ -0,0 +1,13 @@
+-- migrations/007_create_subscription_shares_table.up.sql
+CREATE TABLE subscription_shares (
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    weight BIGINT NOT NULL DEFAULT 0 CHECK (weight >= 0),
+    fixed_amount BIGINT CHECK (fixed_amount >= 0),
+
+    PRIMARY KEY (subscription_id, user_id),
+    -- a member pays either a weighted part or a fixed amount
+    CONSTRAINT valid_share CHECK ((weight > 0) <> (fixed_amount IS NOT NULL))
+);
+
+CREATE INDEX idx_subscription_shares_user_id ON subscription_shares(user_id);
@@ -0,0 +1,2 @@
+-- migrations/008_create_subscription_discounts_table.down.sql
+DROP TABLE IF EXISTS subscription_discounts;
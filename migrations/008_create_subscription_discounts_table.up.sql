@@ -0,0 +1,15 @@
+-- migrations/008_create_subscription_discounts_table.up.sql
+CREATE TABLE subscription_discounts (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value BIGINT NOT NULL CHECK (value > 0),
+    start_date TIMESTAMPTZ,
+    end_date TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_percent CHECK (kind <> 'percent' OR value <= 100),
+    CONSTRAINT valid_period CHECK (start_date IS NULL OR end_date IS NULL OR end_date > start_date)
+);
+
+CREATE INDEX idx_subscription_discounts_subscription_id ON subscription_discounts(subscription_id);
@@ -0,0 +1,6 @@
+-- migrations/009_add_tax_profile.down.sql
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS tax_inclusive,
+    DROP COLUMN IF EXISTS tax_region;
+
+DROP TABLE IF EXISTS tax_rates;
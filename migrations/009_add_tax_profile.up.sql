@@ -0,0 +1,9 @@
+-- migrations/009_add_tax_profile.up.sql
+CREATE TABLE tax_rates (
+    region VARCHAR(16) PRIMARY KEY CHECK (region ~ '^[A-Z]{2}(-[A-Z0-9]{1,3})?$'),
+    rate NUMERIC(6, 4) NOT NULL CHECK (rate >= 0 AND rate < 1)
+);
+
+ALTER TABLE subscriptions
+    ADD COLUMN tax_region VARCHAR(16) REFERENCES tax_rates(region),
+    ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT TRUE;
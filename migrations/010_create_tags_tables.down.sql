@@ -0,0 +1,3 @@
+-- migrations/010_create_tags_tables.down.sql
+DROP TABLE IF EXISTS subscription_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,15 @@
+-- migrations/010_create_tags_tables.up.sql
+CREATE TABLE tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE CHECK (name <> '' AND name = LOWER(name) AND name NOT LIKE '%,%'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE subscription_tags (
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+CREATE INDEX idx_subscription_tags_tag_id ON subscription_tags(tag_id);
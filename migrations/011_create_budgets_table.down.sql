@@ -0,0 +1,2 @@
+-- migrations/011_create_budgets_table.down.sql
+DROP TABLE IF EXISTS budgets;
@@ -0,0 +1,15 @@
+-- migrations/011_create_budgets_table.up.sql
+CREATE TABLE budgets (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    monthly_limit BIGINT NOT NULL CHECK (monthly_limit > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$'),
+    tag_id BIGINT REFERENCES tags(id) ON DELETE CASCADE,
+    service_name VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- a budget covers either every subscription, a tag or a service
+    CONSTRAINT single_budget_scope CHECK (tag_id IS NULL OR service_name IS NULL)
+);
+
+CREATE UNIQUE INDEX unique_budget_scope ON budgets (user_id, COALESCE(tag_id, 0), COALESCE(service_name, ''));
@@ -0,0 +1,34 @@
+-- migrations/012_create_services_catalog.up.sql
+CREATE TABLE services (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE CHECK (name <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE service_aliases (
+    alias VARCHAR(255) PRIMARY KEY CHECK (alias <> '' AND alias = LOWER(alias)),
+    service_id BIGINT NOT NULL REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_service_aliases_service_id ON service_aliases(service_id);
+
+-- one catalog entry per service name, ignoring case and extra whitespace,
+-- named after its earliest spelling
+INSERT INTO services (name)
+SELECT DISTINCT ON (LOWER(REGEXP_REPLACE(BTRIM(service_name), '\s+', ' ', 'g')))
+    REGEXP_REPLACE(BTRIM(service_name), '\s+', ' ', 'g')
+FROM subscriptions
+WHERE BTRIM(service_name) <> ''
+ORDER BY LOWER(REGEXP_REPLACE(BTRIM(service_name), '\s+', ' ', 'g')), created_at;
+
+INSERT INTO service_aliases (alias, service_id)
+SELECT LOWER(name), id FROM services;
+
+ALTER TABLE subscriptions ADD COLUMN service_id BIGINT REFERENCES services(id);
+
+UPDATE subscriptions
+SET service_id = a.service_id
+FROM service_aliases a
+WHERE a.alias = LOWER(REGEXP_REPLACE(BTRIM(subscriptions.service_name), '\s+', ' ', 'g'));
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(service_id);
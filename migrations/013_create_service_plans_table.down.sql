@@ -0,0 +1,4 @@
+-- migrations/013_create_service_plans_table.down.sql
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS plan_id;
+
+DROP TABLE IF EXISTS service_plans;
@@ -0,0 +1,19 @@
+-- migrations/013_create_service_plans_table.up.sql
+CREATE TABLE service_plans (
+    id BIGSERIAL PRIMARY KEY,
+    service_id BIGINT NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL CHECK (name <> ''),
+    price BIGINT NOT NULL CHECK (price >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$'),
+    billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly'
+        CHECK (billing_period IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom')),
+    billing_period_days INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_plan_billing_period_days CHECK ((billing_period = 'custom') = (billing_period_days > 0)),
+    CONSTRAINT unique_service_plan UNIQUE (service_id, name)
+);
+
+ALTER TABLE subscriptions ADD COLUMN plan_id BIGINT REFERENCES service_plans(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_subscriptions_plan_id ON subscriptions(plan_id);
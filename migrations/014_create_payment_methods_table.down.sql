@@ -0,0 +1,4 @@
+-- migrations/014_create_payment_methods_table.down.sql
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS payment_method_id;
+
+DROP TABLE IF EXISTS payment_methods;
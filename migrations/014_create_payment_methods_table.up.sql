@@ -0,0 +1,16 @@
+-- migrations/014_create_payment_methods_table.up.sql
+CREATE TABLE payment_methods (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('card', 'wallet', 'bank')),
+    label VARCHAR(64) NOT NULL CHECK (label <> ''),
+    last_four CHAR(4) CHECK (last_four ~ '^[0-9]{4}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payment_methods_user_id ON payment_methods(user_id);
+
+ALTER TABLE subscriptions
+    ADD COLUMN payment_method_id BIGINT REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_subscriptions_payment_method_id ON subscriptions(payment_method_id);
@@ -0,0 +1,32 @@
+-- migrations/015_store_amounts_in_minor_units.down.sql
+-- back to whole major units, fractions are rounded, limits and discounts stay positive
+CREATE FUNCTION pg_temp.minor_units(currency TEXT) RETURNS BIGINT AS $$
+    SELECT CASE
+        WHEN currency IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG',
+                          'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 1
+        WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 1000
+        WHEN currency IN ('CLF', 'UYW') THEN 10000
+        ELSE 100
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE subscriptions SET price = ROUND(price::NUMERIC / pg_temp.minor_units(currency));
+
+UPDATE subscription_price_changes c
+SET price = ROUND(c.price::NUMERIC / pg_temp.minor_units(s.currency))
+FROM subscriptions s
+WHERE s.id = c.subscription_id;
+
+UPDATE subscription_discounts d
+SET value = GREATEST(ROUND(d.value::NUMERIC / pg_temp.minor_units(s.currency)), 1)
+FROM subscriptions s
+WHERE s.id = d.subscription_id AND d.kind = 'fixed';
+
+UPDATE subscription_shares sh
+SET fixed_amount = ROUND(sh.fixed_amount::NUMERIC / pg_temp.minor_units(s.currency))
+FROM subscriptions s
+WHERE s.id = sh.subscription_id AND sh.fixed_amount IS NOT NULL;
+
+UPDATE service_plans SET price = ROUND(price::NUMERIC / pg_temp.minor_units(currency));
+
+UPDATE budgets SET monthly_limit = GREATEST(ROUND(monthly_limit::NUMERIC / pg_temp.minor_units(currency)), 1);
@@ -0,0 +1,7 @@
+-- migrations/016_add_auto_renew.down.sql
+DROP FUNCTION IF EXISTS subscription_renewals(TIMESTAMPTZ, TIMESTAMPTZ, TIMESTAMPTZ, TEXT, INT, TIMESTAMPTZ, TIMESTAMPTZ);
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS valid_fixed_term,
+    DROP COLUMN IF EXISTS cancellation_notice_days,
+    DROP COLUMN IF EXISTS auto_renew;
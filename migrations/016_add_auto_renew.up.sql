@@ -0,0 +1,47 @@
+-- migrations/016_add_auto_renew.up.sql
+ALTER TABLE subscriptions
+    ADD COLUMN auto_renew BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN cancellation_notice_days INT NOT NULL DEFAULT 0 CHECK (cancellation_notice_days >= 0),
+    -- a fixed-term subscription runs until its end date
+    ADD CONSTRAINT valid_fixed_term
+        CHECK (auto_renew OR (end_date IS NOT NULL AND end_date <> '0001-01-01'::timestamp));
+
+-- subscription_renewals returns the charges within [from_date, to_date) that
+-- renew a subscription, the ones after its start date and before its end
+-- date, the same way entity.Subscription.ChargeDate counts them from anchor
+CREATE FUNCTION subscription_renewals(
+    anchor TIMESTAMPTZ,
+    start_date TIMESTAMPTZ,
+    end_date TIMESTAMPTZ,
+    billing_period TEXT,
+    billing_period_days INT,
+    from_date TIMESTAMPTZ,
+    to_date TIMESTAMPTZ
+) RETURNS SETOF TIMESTAMPTZ AS $$
+DECLARE
+    step INTERVAL;
+    charge TIMESTAMPTZ;
+    n INT := 0;
+BEGIN
+    step := CASE billing_period
+        WHEN 'weekly' THEN INTERVAL '7 days'
+        WHEN 'quarterly' THEN INTERVAL '3 months'
+        WHEN 'yearly' THEN INTERVAL '12 months'
+        WHEN 'custom' THEN make_interval(days => billing_period_days)
+        ELSE INTERVAL '1 month'
+    END;
+    IF step <= INTERVAL '0' THEN
+        RETURN;
+    END IF;
+
+    LOOP
+        charge := anchor + n * step;
+        EXIT WHEN charge >= to_date
+            OR (end_date IS NOT NULL AND end_date <> '0001-01-01'::timestamp AND charge >= end_date);
+        IF charge > start_date AND charge >= from_date THEN
+            RETURN NEXT charge;
+        END IF;
+        n := n + 1;
+    END LOOP;
+END
+$$ LANGUAGE plpgsql STABLE;
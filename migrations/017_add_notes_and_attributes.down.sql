@@ -0,0 +1,6 @@
+-- migrations/017_add_notes_and_attributes.down.sql
+DROP INDEX IF EXISTS idx_subscriptions_attributes;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS attributes,
+    DROP COLUMN IF EXISTS notes;
@@ -0,0 +1,7 @@
+-- migrations/017_add_notes_and_attributes.up.sql
+ALTER TABLE subscriptions
+    ADD COLUMN notes TEXT NOT NULL DEFAULT '',
+    ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(attributes) = 'object');
+
+-- serves the attr.<key>=<value> list filters, which test containment
+CREATE INDEX idx_subscriptions_attributes ON subscriptions USING GIN (attributes jsonb_path_ops);
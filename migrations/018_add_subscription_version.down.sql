@@ -0,0 +1,3 @@
+-- migrations/018_add_subscription_version.down.sql
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS version;
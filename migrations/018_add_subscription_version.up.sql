@@ -0,0 +1,4 @@
+-- migrations/018_add_subscription_version.up.sql
+-- bumped on every update, a write based on an older version is refused
+ALTER TABLE subscriptions
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1 CHECK (version > 0);
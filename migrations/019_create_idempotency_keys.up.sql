@@ -0,0 +1,14 @@
+-- migrations/019_create_idempotency_keys.up.sql
+-- the response to a request made with an Idempotency-Key, replayed on retry
+CREATE TABLE idempotency_keys (
+    operation     TEXT NOT NULL,
+    key           TEXT NOT NULL,
+    request_hash  TEXT NOT NULL,
+    status_code   INT,   -- NULL while the first request is in progress
+    response_body BYTEA,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (operation, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,6 @@
+-- migrations/020_unique_subscription_per_service.down.sql
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS unique_subscription;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT unique_subscription UNIQUE (user_id, service_name, start_date);
@@ -0,0 +1,8 @@
+-- migrations/020_unique_subscription_per_service.up.sql
+-- a subscription is unique to the catalog entry of its service, however the
+-- service name was spelled
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS unique_subscription;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT unique_subscription UNIQUE (user_id, service_id, start_date);
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is implemented by both the pool and a transaction.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// WithinTx runs fn in a transaction, queries issued through Querier with the
+// context passed to fn take part in it. A nested call joins the outer
+// transaction through a savepoint, so the outer one can go on after it fails.
+func (p *Postgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = p.Pool.Begin(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("postgres - WithinTx - Begin: %w", err)
+	}
+	// rollback is a no-op once the transaction is committed
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres - WithinTx - Commit: %w", err)
+	}
+
+	return nil
+}
+
+// Querier returns the transaction carried by ctx, or the pool outside of one.
+func (p *Postgres) Querier(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return p.Pool
+}